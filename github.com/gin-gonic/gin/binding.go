@@ -0,0 +1,100 @@
+package gin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const eventValidationFailure = "validation_failure"
+
+// validationRecordedKey is the context key holding the validation failures already recorded,
+// so that a failure returned by ShouldBind and then attached with c.Error is recorded once.
+const validationRecordedKey = "github.com/otel-contrib/instrumentation/github.com/gin-gonic/gin/validation-recorded"
+
+type configType struct{}
+
+var configContextKey = &configType{}
+
+// configFromContext returns the config of the OTel middleware serving the request of c, if any.
+func configFromContext(c *gin.Context) *config {
+	cfg, _ := c.Request.Context().Value(configContextKey).(*config)
+	return cfg
+}
+
+func contextWithConfig(ctx context.Context, cfg *config) context.Context {
+	return context.WithValue(ctx, configContextKey, cfg)
+}
+
+// ShouldBind is c.ShouldBind, recording validation failures on the span of the request.
+func ShouldBind(c *gin.Context, obj interface{}) error {
+	return recordBinding(c, c.ShouldBind(obj))
+}
+
+// ShouldBindJSON is c.ShouldBindJSON, recording validation failures on the span of the request.
+func ShouldBindJSON(c *gin.Context, obj interface{}) error {
+	return recordBinding(c, c.ShouldBindJSON(obj))
+}
+
+// ShouldBindQuery is c.ShouldBindQuery, recording validation failures on the span of the request.
+func ShouldBindQuery(c *gin.Context, obj interface{}) error {
+	return recordBinding(c, c.ShouldBindQuery(obj))
+}
+
+// ShouldBindUri is c.ShouldBindUri, recording validation failures on the span of the request.
+func ShouldBindUri(c *gin.Context, obj interface{}) error {
+	return recordBinding(c, c.ShouldBindUri(obj))
+}
+
+// ShouldBindHeader is c.ShouldBindHeader, recording validation failures on the span of the request.
+func ShouldBindHeader(c *gin.Context, obj interface{}) error {
+	return recordBinding(c, c.ShouldBindHeader(obj))
+}
+
+// ShouldBindWith is c.ShouldBindWith, recording validation failures on the span of the request.
+func ShouldBindWith(c *gin.Context, obj interface{}, b binding.Binding) error {
+	return recordBinding(c, c.ShouldBindWith(obj, b))
+}
+
+func recordBinding(c *gin.Context, err error) error {
+	if cfg := configFromContext(c); cfg != nil && err != nil {
+		cfg.recordValidationErrors(c, trace.SpanFromContext(c.Request.Context()), err)
+	}
+	return err
+}
+
+// recordValidationErrors adds an event per failed field validation not recorded yet and counts them.
+// The field values are never recorded, as they may hold personal data.
+func (cfg *config) recordValidationErrors(c *gin.Context, span trace.Span, err error) {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return
+	}
+	recorded, _ := c.Get(validationRecordedKey)
+	seen, ok := recorded.(map[validator.FieldError]bool)
+	if !ok {
+		seen = make(map[validator.FieldError]bool)
+		c.Set(validationRecordedKey, seen)
+	}
+	route := c.FullPath()
+	for _, fe := range errs {
+		if seen[fe] {
+			continue
+		}
+		seen[fe] = true
+		span.AddEvent(eventValidationFailure, trace.WithAttributes(
+			LabelKeyValidationField.String(fe.Field()),
+			LabelKeyValidationTag.String(fe.Tag()),
+			LabelKeyValidationNamespace.String(fe.Namespace()),
+		))
+		cfg.metricValidationFailures.Add(c.Request.Context(), 1,
+			semconv.HTTPRouteKey.String(route),
+			LabelKeyValidationField.String(fe.Field()),
+		)
+	}
+}
@@ -0,0 +1,32 @@
+package gin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/otel-contrib/instrumentation/internal/bodycapture"
+)
+
+// BodyRedactor rewrites a captured body before it is recorded, e.g. to mask secrets.
+type BodyRedactor = bodycapture.Redactor
+
+// bodyCaptureWriter records what the handlers write into a bodycapture.Buffer.
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	buf *bodycapture.Buffer
+}
+
+func wrapBodyCaptureWriter(w gin.ResponseWriter, bc *bodycapture.Config) (gin.ResponseWriter, *bodycapture.Buffer) {
+	buf := bc.NewBuffer()
+	return &bodyCaptureWriter{ResponseWriter: w, buf: buf}, buf
+}
+
+func (w *bodyCaptureWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	_, _ = w.buf.Write(p[:n])
+	return n, err
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	_, _ = w.buf.Write([]byte(s[:n]))
+	return n, err
+}
@@ -1,13 +1,72 @@
 package gin
 
+import (
+	"github.com/otel-contrib/instrumentation/internal/bodycapture"
+	"github.com/otel-contrib/instrumentation/internal/dependency"
+	"github.com/otel-contrib/instrumentation/internal/exception"
+	"github.com/otel-contrib/instrumentation/internal/stream"
+	"github.com/otel-contrib/instrumentation/internal/tlsinfo"
+	"go.opentelemetry.io/otel/label"
+)
+
 const (
 	defaultInstrumentationName = "github.com/otel-contrib/instrumentation/github.com/gin-gonic/gin"
 	defaultServerName          = "gin"
 	defaultOperationName       = "gin"
 )
 
+// Semantic conventions for attribute keys for gin.
+const (
+	LabelKeyTLSVersion              = tlsinfo.LabelKeyVersion
+	LabelKeyTLSCipherSuite          = tlsinfo.LabelKeyCipherSuite
+	LabelKeyTLSALPNProtocol         = tlsinfo.LabelKeyALPNProtocol
+	LabelKeyTLSServerName           = tlsinfo.LabelKeyServerName
+	LabelKeyTLSPeerSubject          = tlsinfo.LabelKeyPeerSubject
+	LabelKeyTLSPeerIssuer           = tlsinfo.LabelKeyPeerIssuer
+	LabelKeyHTTPDeadlineBudget      = label.Key("http.deadline_budget_ms")
+	LabelKeyGinHandler              = label.Key("gin.handler")
+	LabelKeyGinHandlerAborted       = label.Key("gin.handler.aborted")
+	LabelKeyErrorType               = label.Key("error.type")
+	LabelKeyErrorMessage            = label.Key("error.message")
+	LabelKeyErrorMeta               = label.Key("error.meta")
+	LabelKeyValidationField         = label.Key("validation.field")
+	LabelKeyValidationTag           = label.Key("validation.tag")
+	LabelKeyValidationNamespace     = label.Key("validation.namespace")
+	LabelKeyExceptionType           = exception.LabelKeyType
+	LabelKeyExceptionMessage        = exception.LabelKeyMessage
+	LabelKeyExceptionStacktrace     = exception.LabelKeyStacktrace
+	LabelKeyHTTPStreamKind          = stream.LabelKeyKind
+	LabelKeyHTTPStreamMessages      = stream.LabelKeyMessages
+	LabelKeyHTTPStreamBytes         = stream.LabelKeyBytes
+	LabelKeyHTTPStreamCloseReason   = stream.LabelKeyCloseReason
+	LabelKeyHTTPTimeToFirstByte     = stream.LabelKeyTimeToFirstByte
+	LabelKeyGinTemplate             = label.Key("gin.template")
+	LabelKeyGinRenderDuration       = label.Key("gin.render.duration_ms")
+	LabelKeyGinRenderSize           = label.Key("gin.render.size")
+	LabelKeyHTTPBody                = bodycapture.LabelKeyBody
+	LabelKeyHTTPBodyTruncated       = bodycapture.LabelKeyBodyTruncated
+	LabelKeyDBQueryCount            = dependency.LabelKeyDBQueryCount
+	LabelKeyDBTotalDuration         = dependency.LabelKeyDBTotalDuration
+	LabelKeyRedisCmdCount           = dependency.LabelKeyRedisCmdCount
+	LabelKeyRedisTotalDuration      = dependency.LabelKeyRedisTotalDuration
+	LabelKeyHTTPClientCallCount     = dependency.LabelKeyHTTPClientCallCount
+	LabelKeyHTTPClientTotalDuration = dependency.LabelKeyHTTPClientTotalDuration
+	LabelKeyDependencyKind          = dependency.LabelKeyKind
+)
+
 // Metrics semantic conventions
 const (
-	metricHTTPServerDuration     = "http.server.duration"      // Incoming end to end duration, milliseconds
-	metricHTTPServerRequestCount = "http.server.request_count" // Incoming request count total
+	metricHTTPServerDuration               = "http.server.duration"                 // Incoming end to end duration, milliseconds
+	metricHTTPServerRequestCount           = "http.server.request_count"            // Incoming request count total
+	metricHTTPServerErrorCount             = "http.server.error_count"              // handler errors attached to the context count total
+	metricHTTPServerValidationFailures     = "http.server.validation_failures"      // failed field validations count total
+	metricHTTPServerTimeToFirstByte        = "http.server.time_to_first_byte"       // time to the first byte of streamed responses and hijacked connections, milliseconds
+	metricHTTPServerStreamMessageCount     = "http.server.stream_message_count"     // flushes of streamed responses and writes to hijacked connections count total
+	metricHTTPServerStreamBytes            = "http.server.stream_bytes"             // bytes written to streamed responses and hijacked connections total
+	metricHTTPServerRenderDuration         = "http.server.render_duration"          // template render time, milliseconds
+	metricHTTPServerPanics                 = "http.server.panics"                   // recovered panic count total
+	metricHTTPServerHandlerDuration        = "http.server.handler_duration"         // per handler duration, milliseconds
+	metricHTTPServerDeadlineExhaustedCount = "http.server.deadline_exhausted_count" // requests arriving with no deadline budget left count total
+	metricHTTPServerDependencyCalls        = "http.server.dependency_calls"         // database, cache and upstream HTTP calls per request making any, labelled by dependency kind
+	metricHTTPServerDependencyDuration     = "http.server.dependency_duration"      // time spent in database, cache and upstream HTTP calls per request making any, milliseconds
 )
@@ -1,7 +1,13 @@
 package gin
 
 import (
+	"net"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	otelzap "github.com/otel-contrib/instrumentation/go.uber.org/zap"
+	"github.com/otel-contrib/instrumentation/internal/bodycapture"
+	"github.com/otel-contrib/instrumentation/internal/header"
 	"go.opentelemetry.io/contrib"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/metric"
@@ -17,11 +23,35 @@ type config struct {
 	serverName        string
 	operationName     string
 	spanNameFormatter SpanNameFormatter
+	requestHeaders    []string
+	responseHeaders   []string
+	sensitiveHeaders  header.Set
+	unmaskedHeaders   []string
+	bodyCapture       bodycapture.Config
+	deadlineHeader    string
+	traceHeaders      traceHeaders
+	trustedProxies    []string
+	trustedNets       []*net.IPNet
+	trustFunc         TrustFunc
+	untrustedContext  UntrustedContext
+	errorStatusTypes  ErrorType
+	logger            *otelzap.Logger
 
-	tracer             trace.Tracer
-	meter              metric.Meter
-	metricDuration     metric.Int64ValueRecorder
-	metricRequestCount metric.Int64Counter
+	tracer                   trace.Tracer
+	meter                    metric.Meter
+	metricDuration           metric.Int64ValueRecorder
+	metricRequestCount       metric.Int64Counter
+	metricDeadlineExhausted  metric.Int64Counter
+	metricDependencyCalls    metric.Int64ValueRecorder
+	metricDependencyDuration metric.Int64ValueRecorder
+	metricHandlerDuration    metric.Int64ValueRecorder
+	metricErrorCount         metric.Int64Counter
+	metricValidationFailures metric.Int64Counter
+	metricPanics             metric.Int64Counter
+	metricTimeToFirstByte    metric.Int64ValueRecorder
+	metricStreamMessageCount metric.Int64Counter
+	metricStreamBytes        metric.Int64Counter
+	metricRenderDuration     metric.Int64ValueRecorder
 }
 
 // Option applies a configuration to the given config.
@@ -88,6 +118,140 @@ func WithSpanNameFormatter(f SpanNameFormatter) Option {
 	})
 }
 
+// WithCapturedRequestHeaders specifies request headers recorded as http.request.header.<name> attributes.
+func WithCapturedRequestHeaders(headers ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.requestHeaders = append(c.requestHeaders, header.CanonicalKeys(headers)...)
+	})
+}
+
+// WithCapturedResponseHeaders specifies response headers recorded as http.response.header.<name> attributes.
+func WithCapturedResponseHeaders(headers ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.responseHeaders = append(c.responseHeaders, header.CanonicalKeys(headers)...)
+	})
+}
+
+// WithSensitiveHeaders specifies headers whose captured values are masked, in addition to
+// Authorization, Proxy-Authorization, Cookie and Set-Cookie, which are always masked
+// unless unmasked with WithUnmaskedHeaders.
+func WithSensitiveHeaders(headers ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.sensitiveHeaders.Add(headers...)
+	})
+}
+
+// WithUnmaskedHeaders specifies headers whose captured values are recorded as is,
+// even though they are masked by default or given to WithSensitiveHeaders.
+// Only use it for headers known to carry no credentials in your deployment.
+func WithUnmaskedHeaders(headers ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.unmaskedHeaders = append(c.unmaskedHeaders, headers...)
+	})
+}
+
+// WithBodyCapture enables recording up to maxBytes of the request and response bodies
+// as span events. Bodies are only recorded for responses with a status code of 400 or above,
+// and still stream through to the handlers and the client unchanged.
+func WithBodyCapture(maxBytes int) Option {
+	return OptionFunc(func(c *config) {
+		c.bodyCapture.MaxBytes = maxBytes
+	})
+}
+
+// WithBodyCaptureContentTypes specifies the content types captured by WithBodyCapture.
+// A type ending in /* matches any subtype.
+// If none is specified, JSON, XML, form and text bodies are captured.
+func WithBodyCaptureContentTypes(contentTypes ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.bodyCapture.ContentTypes = contentTypes
+	})
+}
+
+// WithBodyRedactor specifies a redactor applied to captured bodies before they are recorded.
+func WithBodyRedactor(r BodyRedactor) Option {
+	return OptionFunc(func(c *config) {
+		c.bodyCapture.Redactor = r
+	})
+}
+
+// WithDeadlineHeader enables deadline propagation through the given header.
+// Requests carrying the header, set to the time left before the caller's deadline in milliseconds,
+// are served with a context bounded by it, unless WithTrustedProxies or WithTrustFunc find them untrusted.
+func WithDeadlineHeader(name string) Option {
+	return OptionFunc(func(c *config) {
+		c.deadlineHeader = http.CanonicalHeaderKey(name)
+	})
+}
+
+// WithTraceResponse makes the middleware send the W3C traceresponse header,
+// carrying the trace ID and span ID of the server span.
+func WithTraceResponse() Option {
+	return OptionFunc(func(c *config) {
+		c.traceHeaders.TraceResponse = true
+	})
+}
+
+// WithServerTiming makes the middleware send a Server-Timing header with the total
+// handler time and the time spent in the database, cache and upstream HTTP calls made
+// through this module's instrumentation while handling the request.
+func WithServerTiming() Option {
+	return OptionFunc(func(c *config) {
+		c.traceHeaders.ServerTiming = true
+	})
+}
+
+// WithTraceHeadersFilter specifies which requests get the traceresponse and Server-Timing headers.
+// If none is specified, every request gets them once enabled.
+func WithTraceHeadersFilter(f TraceHeadersFilter) Option {
+	return OptionFunc(func(c *config) {
+		c.traceHeaders.filter = f
+	})
+}
+
+// WithTrustedProxies specifies the CIDR blocks of the peers whose trace context and baggage
+// are extracted. Requests from other peers are handled as specified by WithUntrustedContext.
+// The peer is the remote address of the connection, not the client IP reported by proxies.
+// If neither WithTrustedProxies nor WithTrustFunc is specified, every request is trusted.
+func WithTrustedProxies(cidrs ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.trustedProxies = append(c.trustedProxies, cidrs...)
+	})
+}
+
+// WithTrustFunc specifies a function deciding which requests have their trace context and
+// baggage extracted. A request is trusted if either the function or WithTrustedProxies accepts it.
+func WithTrustFunc(f TrustFunc) Option {
+	return OptionFunc(func(c *config) {
+		c.trustFunc = f
+	})
+}
+
+// WithUntrustedContext specifies what happens to the trace context of untrusted requests.
+// Their baggage is always discarded.
+// If none is specified, UntrustedContextLink is used.
+func WithUntrustedContext(u UntrustedContext) Option {
+	return OptionFunc(func(c *config) {
+		c.untrustedContext = u
+	})
+}
+
+// WithErrorStatusTypes specifies the types of the context errors that set the span status to Error,
+// whatever the response status code. Every error is recorded as a span event regardless.
+// If none is specified, private errors set the span status.
+func WithErrorStatusTypes(t ErrorType) Option {
+	return OptionFunc(func(c *config) {
+		c.errorStatusTypes = t
+	})
+}
+
+// WithLogger specifies the logger returned by LoggerFromContext.
+func WithLogger(logger *otelzap.Logger) Option {
+	return OptionFunc(func(c *config) {
+		c.logger = logger
+	})
+}
+
 func newConfig(opts ...Option) (*config, error) {
 	var err error
 	c := &config{
@@ -97,10 +261,19 @@ func newConfig(opts ...Option) (*config, error) {
 		serverName:        defaultServerName,
 		operationName:     defaultOperationName,
 		spanNameFormatter: defaultSpanNameFormatter,
+		sensitiveHeaders:  header.NewSet(header.DefaultSensitive),
+		bodyCapture:       bodycapture.Config{ContentTypes: bodycapture.DefaultContentTypes},
+		errorStatusTypes:  ErrorTypePrivate,
 	}
 	for _, opt := range opts {
 		opt.Apply(c)
 	}
+	c.sensitiveHeaders.Remove(c.unmaskedHeaders...)
+	if c.trustedProxies != nil {
+		if c.trustedNets, err = parseCIDRs(c.trustedProxies); err != nil {
+			return nil, err
+		}
+	}
 
 	c.tracer = c.tracerProvider.Tracer(
 		defaultInstrumentationName,
@@ -127,6 +300,121 @@ func newConfig(opts ...Option) (*config, error) {
 	if err != nil {
 		return nil, err
 	}
+	c.metricHandlerDuration, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPServerHandlerDuration,
+		metric.WithDescription("handler time in milliseconds"),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricErrorCount, err = c.meter.NewInt64Counter(
+		metricHTTPServerErrorCount,
+		metric.WithDescription("handler error count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricValidationFailures, err = c.meter.NewInt64Counter(
+		metricHTTPServerValidationFailures,
+		metric.WithDescription("validation failure count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricTimeToFirstByte, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPServerTimeToFirstByte,
+		metric.WithDescription("time to first byte in milliseconds"),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricStreamMessageCount, err = c.meter.NewInt64Counter(
+		metricHTTPServerStreamMessageCount,
+		metric.WithDescription("stream message count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricStreamBytes, err = c.meter.NewInt64Counter(
+		metricHTTPServerStreamBytes,
+		metric.WithDescription("stream bytes"),
+		metric.WithUnit(unit.Bytes),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricPanics, err = c.meter.NewInt64Counter(
+		metricHTTPServerPanics,
+		metric.WithDescription("panic count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricDeadlineExhausted, err = c.meter.NewInt64Counter(
+		metricHTTPServerDeadlineExhaustedCount,
+		metric.WithDescription("deadline exhausted request count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricDependencyCalls, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPServerDependencyCalls,
+		metric.WithDescription("dependency calls per request"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricDependencyDuration, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPServerDependencyDuration,
+		metric.WithDescription("dependency time per request in milliseconds"),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// newRenderConfig returns the config of an HTMLRender, which only needs the tracer
+// and the render duration instrument. Only the providers and the operation name
+// given by opts are used.
+func newRenderConfig(opts ...Option) (*config, error) {
+	var err error
+	c := &config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		operationName:  defaultOperationName,
+	}
+	for _, opt := range opts {
+		opt.Apply(c)
+	}
+
+	c.tracer = c.tracerProvider.Tracer(
+		defaultInstrumentationName,
+		trace.WithInstrumentationVersion(contrib.SemVersion()),
+	)
+	c.meter = c.meterProvider.Meter(
+		defaultInstrumentationName,
+		metric.WithInstrumentationVersion(contrib.SemVersion()),
+	)
+
+	c.metricRenderDuration, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPServerRenderDuration,
+		metric.WithDescription("template render time in milliseconds"),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, err
+	}
 
 	return c, nil
 }
@@ -1,7 +1,64 @@
 package gin
 
-import "github.com/gin-gonic/gin"
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	otelzap "github.com/otel-contrib/instrumentation/go.uber.org/zap"
+	"go.opentelemetry.io/contrib"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/trace"
+)
 
 // Context is the most important part of gin. It allows us to pass variables between middleware,
 // manage the flow, validate the JSON of a request and render a JSON response for example.
+//
+// A Context passed as a context.Context only resolves string keys, so the span of the request
+// is not found through it by other instrumentation. The helpers below reach it from the Context,
+// and the instrumentation in this repository looks through a Context to its request.
 type Context = gin.Context
+
+// SpanFromContext returns the span of the request served by c.
+func SpanFromContext(c *gin.Context) trace.Span {
+	return trace.SpanFromContext(c.Request.Context())
+}
+
+// StartSpan starts a child span of the span of the request served by c.
+// The returned context carries the new span and should be passed to the work it covers.
+func StartSpan(c *gin.Context, name string, opts ...trace.SpanOption) (context.Context, trace.Span) {
+	tracer := otel.GetTracerProvider().Tracer(
+		defaultInstrumentationName,
+		trace.WithInstrumentationVersion(contrib.SemVersion()),
+	)
+	if cfg := configFromContext(c); cfg != nil {
+		tracer = cfg.tracer
+	}
+	return tracer.Start(c.Request.Context(), name, opts...)
+}
+
+// SetAttributes sets attributes on the span of the request served by c.
+func SetAttributes(c *gin.Context, kv ...label.KeyValue) {
+	SpanFromContext(c).SetAttributes(kv...)
+}
+
+// Baggage returns the baggage of the request served by c.
+func Baggage(c *gin.Context) label.Set {
+	return baggage.Set(c.Request.Context())
+}
+
+// BaggageValue returns the value of the baggage key of the request served by c.
+func BaggageValue(c *gin.Context, key label.Key) label.Value {
+	return baggage.Value(c.Request.Context(), key)
+}
+
+// LoggerFromContext returns the logger given by WithLogger, adding the trace and span IDs
+// of the request served by c to every message. If none was given, a no-op logger is returned.
+func LoggerFromContext(c *gin.Context) *otelzap.Logger {
+	cfg := configFromContext(c)
+	if cfg == nil || cfg.logger == nil {
+		return otelzap.NewNop()
+	}
+	return cfg.logger.WithContext(c.Request.Context())
+}
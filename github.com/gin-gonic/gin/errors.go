@@ -0,0 +1,85 @@
+package gin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const eventError = "error"
+
+// ErrorType is an unsigned 64-bit error code as defined in the gin spec.
+type ErrorType = gin.ErrorType
+
+// Error types attached to the errors of a Context.
+const (
+	ErrorTypeBind    = gin.ErrorTypeBind
+	ErrorTypeRender  = gin.ErrorTypeRender
+	ErrorTypePrivate = gin.ErrorTypePrivate
+	ErrorTypePublic  = gin.ErrorTypePublic
+	ErrorTypeAny     = gin.ErrorTypeAny
+)
+
+var errorTypeNames = []struct {
+	t    ErrorType
+	name string
+}{
+	{ErrorTypeBind, "bind"},
+	{ErrorTypeRender, "render"},
+	{ErrorTypePrivate, "private"},
+	{ErrorTypePublic, "public"},
+}
+
+// errorTypeName names t after its flags, such as "bind" or "private|public".
+func errorTypeName(t ErrorType) string {
+	if t == ErrorTypeAny {
+		return "any"
+	}
+	var names []string
+	for _, n := range errorTypeNames {
+		if t&n.t != 0 {
+			names = append(names, n.name)
+		}
+	}
+	if len(names) == 0 {
+		return strconv.FormatUint(uint64(t), 10)
+	}
+	return strings.Join(names, "|")
+}
+
+// recordErrors adds an event per error attached to c, counts them, and sets the span
+// status to Error for the first error matching the status error types.
+func (cfg *config) recordErrors(c *gin.Context, span trace.Span) {
+	var statusSet bool
+	route := c.FullPath()
+	for _, e := range c.Errors {
+		typeName := errorTypeName(e.Type)
+		attrs := []label.KeyValue{
+			LabelKeyErrorType.String(typeName),
+			LabelKeyErrorMessage.String(e.Error()),
+		}
+		if e.Meta != nil {
+			attrs = append(attrs, LabelKeyErrorMeta.String(fmt.Sprint(e.Meta)))
+		}
+		span.AddEvent(eventError, trace.WithAttributes(attrs...))
+		if e.IsType(ErrorTypeBind) {
+			cfg.recordValidationErrors(c, span, e.Err)
+		}
+
+		if !statusSet && e.IsType(cfg.errorStatusTypes) {
+			span.SetStatus(codes.Error, e.Error())
+			statusSet = true
+		}
+
+		cfg.metricErrorCount.Add(c.Request.Context(), 1,
+			semconv.HTTPRouteKey.String(route),
+			LabelKeyErrorType.String(typeName),
+		)
+	}
+}
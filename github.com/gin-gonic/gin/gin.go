@@ -1,10 +1,20 @@
 package gin
 
 import (
-	"fmt"
+	"context"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/otel-contrib/instrumentation/internal/bodycapture"
+	"github.com/otel-contrib/instrumentation/internal/deadline"
+	"github.com/otel-contrib/instrumentation/internal/dependency"
+	"github.com/otel-contrib/instrumentation/internal/exception"
+	"github.com/otel-contrib/instrumentation/internal/header"
+	"github.com/otel-contrib/instrumentation/internal/reqctx"
+	"github.com/otel-contrib/instrumentation/internal/stream"
+	"github.com/otel-contrib/instrumentation/internal/tlsinfo"
+	"go.opentelemetry.io/otel/label"
 	"go.opentelemetry.io/otel/semconv"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -48,33 +58,116 @@ func OTel(opts ...Option) (HandlerFunc, error) {
 	return func(c *Context) {
 		start := time.Now()
 
-		ctx := cfg.propagator.Extract(c.Request.Context(), c.Request.Header)
-		ctx, span := cfg.tracer.Start(ctx,
-			cfg.spanNameFormatter(cfg.operationName, c),
+		spanOpts := []trace.SpanOption{
 			trace.WithSpanKind(trace.SpanKindServer),
 			trace.WithAttributes(semconv.NetAttributesFromHTTPRequest("tcp", c.Request)...),
 			trace.WithAttributes(semconv.EndUserAttributesFromHTTPRequest(c.Request)...),
 			trace.WithAttributes(
 				semconv.HTTPServerAttributesFromHTTPRequest(cfg.serverName, c.FullPath(), c.Request)...,
 			),
-		)
-		defer span.End()
+			trace.WithAttributes(
+				header.Attributes(header.RequestPrefix, c.Request.Header, cfg.requestHeaders, cfg.sensitiveHeaders)...,
+			),
+		}
+
+		ctx := c.Request.Context()
+		trusted := cfg.trusted(c)
+		if trusted {
+			ctx = cfg.propagator.Extract(ctx, c.Request.Header)
+		} else {
+			// The span of an untrusted request starts a new trace, even under an outer server span.
+			spanOpts = append(spanOpts, trace.WithNewRoot())
+			if cfg.untrustedContext == UntrustedContextLink {
+				remote := trace.RemoteSpanContextFromContext(cfg.propagator.Extract(ctx, c.Request.Header))
+				if remote.IsValid() {
+					spanOpts = append(spanOpts, trace.WithLinks(trace.Link{SpanContext: remote}))
+				}
+			}
+		}
+		ctx, span := cfg.tracer.Start(ctx, cfg.spanNameFormatter(cfg.operationName, c), spanOpts...)
+		if c.Request.TLS != nil {
+			span.SetAttributes(tlsinfo.Attributes(c.Request.TLS)...)
+		}
+
+		metricLabels := semconv.HTTPServerMetricAttributesFromHTTPRequest(cfg.serverName, c.Request)
+
+		// Like the trace context, the deadline of an untrusted request is ignored.
+		if cfg.deadlineHeader != "" && trusted {
+			if budget, ok := deadline.Extract(cfg.deadlineHeader, c.Request.Header); ok {
+				span.SetAttributes(LabelKeyHTTPDeadlineBudget.Int64(budget.Milliseconds()))
+				if budget <= 0 {
+					cfg.metricDeadlineExhausted.Add(ctx, 1, metricLabels...)
+				}
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, start.Add(budget))
+				defer cancel()
+			}
+		}
+
+		// The span ends with the tracker, once the handlers have returned and any hijacked connection is closed.
+		tracker := stream.NewTracker(ctx, span, start, metricLabels, stream.Metrics{
+			TimeToFirstByte: cfg.metricTimeToFirstByte,
+			MessageCount:    cfg.metricStreamMessageCount,
+			Bytes:           cfg.metricStreamBytes,
+		})
+		defer tracker.HandlerDone(ctx)
+
+		ctx, deps := dependency.ContextWithRecorder(ctx)
+		ctx, panicked := exception.ContextWithPanic(ctx)
 
-		c.Request = c.Request.WithContext(ctx)
+		c.Request = c.Request.WithContext(contextWithConfig(ctx, cfg))
+		// The client instrumentation given c as its context finds the request context through this key.
+		c.Set(reqctx.Key, reqctx.Func(func() context.Context {
+			return c.Request.Context()
+		}))
+
+		var reqBody, respBody *bodycapture.Buffer
+		if cfg.bodyCapture.Enabled() {
+			if c.Request.Body != nil && c.Request.Body != http.NoBody {
+				c.Request.Body, reqBody = cfg.bodyCapture.Tee(c.Request.Body)
+			}
+			c.Writer, respBody = wrapBodyCaptureWriter(c.Writer, &cfg.bodyCapture)
+		}
+		writer := &streamWriter{ResponseWriter: c.Writer, tracker: tracker, c: c}
+		if cfg.traceHeaders.enabled(c) {
+			respHeader := c.Writer.Header()
+			writer.onHeader = func() {
+				cfg.traceHeaders.Set(respHeader, span, start, deps)
+			}
+		}
+		c.Writer = writer
 
 		c.Next()
+		// Headers of a response the handlers did not write are sent once they return.
+		writer.writingHeader()
 
 		statusCode := c.Writer.Status()
+		if tracker.IsHijacked() {
+			statusCode = http.StatusSwitchingProtocols
+		}
 		spanCode, spanMsg := semconv.SpanStatusFromHTTPStatusCode(statusCode)
 		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(statusCode)...)
+		span.SetAttributes(
+			header.Attributes(header.ResponsePrefix, c.Writer.Header(), cfg.responseHeaders, cfg.sensitiveHeaders)...,
+		)
 		span.SetStatus(spanCode, spanMsg)
-		if len(c.Errors) > 0 {
-			span.RecordError(fmt.Errorf(c.Errors.String()))
+		if cfg.bodyCapture.Enabled() && statusCode >= http.StatusBadRequest {
+			cfg.bodyCapture.Record(span, bodycapture.EventRequestBody, c.Request.Header.Get("Content-Type"), reqBody)
+			cfg.bodyCapture.Record(span, bodycapture.EventResponseBody, c.Writer.Header().Get("Content-Type"), respBody)
 		}
+		cfg.recordErrors(c, span)
+		// A panic recovered after the response was written keeps the span as Error.
+		panicked.SetStatus(span)
+		deps.Record(ctx, span, dependency.Metrics{
+			Calls:    cfg.metricDependencyCalls,
+			Duration: cfg.metricDependencyDuration,
+		}, []label.KeyValue{semconv.HTTPRouteKey.String(c.FullPath())})
 
-		metricLabels := semconv.HTTPServerMetricAttributesFromHTTPRequest(cfg.serverName, c.Request)
 		cfg.metricRequestCount.Add(ctx, 1, metricLabels...)
-		elapsedTime := time.Since(start).Milliseconds()
-		cfg.metricDuration.Record(ctx, elapsedTime, metricLabels...)
+		// Streamed responses and hijacked connections would skew the request duration.
+		if !tracker.Streaming() {
+			elapsedTime := time.Since(start).Milliseconds()
+			cfg.metricDuration.Record(ctx, elapsedTime, metricLabels...)
+		}
 	}, nil
 }
@@ -0,0 +1,86 @@
+package gin
+
+import (
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/otel-contrib/instrumentation/internal/exception"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// abortClaimedKey is the context key set by the innermost handler that finds the context
+// newly aborted, so that the middleware it returns through are not reported as aborting.
+const abortClaimedKey = "github.com/otel-contrib/instrumentation/github.com/gin-gonic/gin/abort-claimed"
+
+// HandlersChain defines a HandlerFunc array.
+type HandlersChain = gin.HandlersChain
+
+// WrapHandlers wraps the given handlers so that each records a child span named after it,
+// along with its duration and whether it aborted the request, when it serves a request
+// that went through the OTel middleware. Handlers that call c.Next() enclose the spans
+// of the handlers they run.
+//
+//	r.GET("/users/:id", gin.WrapHandlers(auth, getUser)...)
+func WrapHandlers(handlers ...HandlerFunc) HandlersChain {
+	chain := make(HandlersChain, len(handlers))
+	for i, h := range handlers {
+		chain[i] = handlerSpan(nameOfFunction(h), h)
+	}
+	return chain
+}
+
+func handlerSpan(name string, h gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := configFromContext(c)
+		if cfg == nil {
+			h(c)
+			return
+		}
+
+		start := time.Now()
+		route := c.FullPath()
+
+		parent := trace.SpanFromContext(c.Request.Context())
+		ctx, span := cfg.tracer.Start(c.Request.Context(), name,
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(LabelKeyGinHandler.String(name)),
+		)
+		c.Request = c.Request.WithContext(ctx)
+
+		wasAborted := c.IsAborted()
+		defer func() {
+			// A panicking handler still ends its span, which records the panic before it goes on
+			// to the recovery middleware, and hands the parent span back to the middleware it returns through.
+			if r := recover(); r != nil {
+				exception.Record(ctx, span, r, debug.Stack())
+				defer panic(r)
+			}
+
+			aborted := !wasAborted && c.IsAborted() && !c.GetBool(abortClaimedKey)
+			if aborted {
+				c.Set(abortClaimedKey, true)
+			}
+
+			span.SetAttributes(LabelKeyGinHandlerAborted.Bool(aborted))
+			span.End()
+			// Only the span is restored, keeping the values the handler added to the context.
+			ctx := trace.ContextWithSpan(c.Request.Context(), parent)
+			c.Request = c.Request.WithContext(ctx)
+
+			metricLabels := []label.KeyValue{semconv.HTTPRouteKey.String(route), LabelKeyGinHandler.String(name)}
+			elapsedTime := time.Since(start).Milliseconds()
+			cfg.metricHandlerDuration.Record(ctx, elapsedTime, metricLabels...)
+		}()
+		h(c)
+	}
+}
+
+// nameOfFunction returns the name gin reports for f in c.HandlerNames().
+func nameOfFunction(f interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
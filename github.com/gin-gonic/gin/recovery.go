@@ -1,8 +1,75 @@
 package gin
 
-import "github.com/gin-gonic/gin"
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	otelzap "github.com/otel-contrib/instrumentation/go.uber.org/zap"
+	"github.com/otel-contrib/instrumentation/internal/exception"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
 
 // Recovery returns a middleware that recovers from any panics and writes a 500 if there was one.
+// The panic is recorded as an exception event on the span of the request, which is marked as Error,
+// and logged to gin.DefaultErrorWriter.
 func Recovery() HandlerFunc {
-	return gin.Recovery()
+	return RecoveryWithLogger(nil)
+}
+
+// RecoveryWithLogger returns a middleware that recovers from any panics as Recovery does,
+// logging them to logger along with the trace and span IDs.
+func RecoveryWithLogger(logger *otelzap.Logger) HandlerFunc {
+	return func(c *Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			ctx := c.Request.Context()
+			stack := debug.Stack()
+			exception.Record(ctx, trace.SpanFromContext(ctx), r, stack)
+			if cfg := configFromContext(c); cfg != nil {
+				cfg.metricPanics.Add(ctx, 1, semconv.HTTPRouteKey.String(c.FullPath()))
+			}
+			if logger != nil {
+				logger.ErrorWithContext(ctx, "panic recovered",
+					zap.String("panic", fmt.Sprint(r)),
+					zap.ByteString("stacktrace", stack),
+				)
+			} else if gin.DefaultErrorWriter != nil {
+				log.New(gin.DefaultErrorWriter, "", log.LstdFlags).Printf("[Recovery] panic recovered:\n%v\n%s", r, stack)
+			}
+
+			// If the connection is dead, we can't write a status to it.
+			if err, ok := r.(error); ok && isBrokenPipe(err) {
+				c.Error(err) // nolint: errcheck
+				c.Abort()
+				return
+			}
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+		c.Next()
+	}
+}
+
+func isBrokenPipe(err error) bool {
+	ne, ok := err.(*net.OpError)
+	if !ok {
+		return false
+	}
+	se, ok := ne.Err.(*os.SyscallError)
+	if !ok {
+		return false
+	}
+	msg := strings.ToLower(se.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
 }
@@ -0,0 +1,161 @@
+package gin
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/gin-gonic/gin/render"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HTMLRender is the interface of the template renderers of an Engine.
+type HTMLRender = render.HTMLRender
+
+type otelHTMLRender struct {
+	render HTMLRender
+
+	operationName string
+
+	tracer         trace.Tracer
+	meter          metric.Meter
+	metricDuration metric.Int64ValueRecorder
+}
+
+var _ HTMLRender = &otelHTMLRender{}
+
+type otelHTMLInstance struct {
+	instance render.Render
+
+	name string
+	r    *otelHTMLRender
+}
+
+// contextWriter is implemented by the response writer installed by the OTel middleware,
+// as renderers are only given the response writer.
+type contextWriter interface {
+	requestContext() context.Context
+}
+
+// maxUnwrapDepth bounds the number of response writers looked through by requestContextOf.
+const maxUnwrapDepth = 16
+
+// responseSizeWriter counts the bytes written to the response.
+type responseSizeWriter struct {
+	http.ResponseWriter
+	size int64
+}
+
+// NewHTMLRender wraps the provided HTMLRender with one that starts a child span of the request span
+// for every c.HTML call. As LoadHTMLGlob and LoadHTMLFiles replace the HTMLRender of the engine,
+// it is meant to wrap the HTMLRender they set:
+//
+//	e.LoadHTMLGlob("templates/*")
+//	e.HTMLRender, err = gin.NewHTMLRender(e.HTMLRender)
+//
+// Only WithTracerProvider, WithMeterProvider and WithOperationName, which names the spans
+// <operation>.render, apply to the renderer; the other options are ignored.
+func NewHTMLRender(r HTMLRender, opts ...Option) (HTMLRender, error) {
+	c, err := newRenderConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &otelHTMLRender{
+		render:         r,
+		operationName:  c.operationName,
+		tracer:         c.tracer,
+		meter:          c.meter,
+		metricDuration: c.metricRenderDuration,
+	}, nil
+}
+
+func (r *otelHTMLRender) Instance(name string, data interface{}) render.Render {
+	return &otelHTMLInstance{instance: r.render.Instance(name, data), name: name, r: r}
+}
+
+func (i *otelHTMLInstance) Render(w http.ResponseWriter) error {
+	start := time.Now()
+
+	ctx := requestContextOf(w)
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		ctx, span = i.r.tracer.Start(ctx, i.r.operationName+".render",
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(LabelKeyGinTemplate.String(i.name)),
+		)
+		defer span.End()
+	}
+
+	sw := &responseSizeWriter{ResponseWriter: w}
+	err := i.instance.Render(sw)
+	elapsedTime := time.Since(start).Milliseconds()
+
+	if span.IsRecording() {
+		span.SetAttributes(
+			LabelKeyGinRenderDuration.Int64(elapsedTime),
+			LabelKeyGinRenderSize.Int64(sw.size),
+		)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	i.r.metricDuration.Record(ctx, elapsedTime, LabelKeyGinTemplate.String(i.name))
+
+	return err
+}
+
+// requestContextOf returns the request context carried by the response writer installed by
+// the OTel middleware, looking through the writers that middleware after it wrapped it in,
+// either by embedding it or behind an Unwrap method. It returns the background context
+// if there is none.
+func requestContextOf(w http.ResponseWriter) context.Context {
+	for depth := 0; w != nil && depth < maxUnwrapDepth; depth++ {
+		if cw, ok := w.(contextWriter); ok {
+			return cw.requestContext()
+		}
+		if u, ok := w.(interface{ Unwrap() http.ResponseWriter }); ok {
+			w = u.Unwrap()
+			continue
+		}
+		w = embeddedWriter(w)
+	}
+	return context.Background()
+}
+
+// embeddedWriter returns the response writer embedded in the struct w points to, if any.
+func embeddedWriter(w http.ResponseWriter) http.ResponseWriter {
+	v := reflect.ValueOf(w)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Type().Field(i); !f.Anonymous || f.PkgPath != "" {
+			continue
+		}
+		if inner, ok := v.Field(i).Interface().(http.ResponseWriter); ok {
+			return inner
+		}
+	}
+	return nil
+}
+
+func (i *otelHTMLInstance) WriteContentType(w http.ResponseWriter) {
+	i.instance.WriteContentType(w)
+}
+
+func (w *responseSizeWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.size += int64(n)
+	return n, err
+}
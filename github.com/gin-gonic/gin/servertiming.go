@@ -0,0 +1,20 @@
+package gin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/otel-contrib/instrumentation/internal/servertiming"
+)
+
+// TraceHeadersFilter reports whether the traceresponse and Server-Timing headers
+// are sent in response to the request of c, e.g. to keep them from external clients.
+type TraceHeadersFilter func(c *gin.Context) bool
+
+// traceHeaders decides which of the traceresponse and Server-Timing headers are sent, and to which requests.
+type traceHeaders struct {
+	servertiming.Headers
+	filter TraceHeadersFilter
+}
+
+func (th *traceHeaders) enabled(c *gin.Context) bool {
+	return th.Headers.Enabled() && (th.filter == nil || th.filter(c))
+}
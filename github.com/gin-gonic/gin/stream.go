@@ -0,0 +1,68 @@
+package gin
+
+import (
+	"bufio"
+	"context"
+	"net"
+
+	"github.com/gin-gonic/gin"
+	"github.com/otel-contrib/instrumentation/internal/stream"
+)
+
+// streamWriter reports the writes, flushes and hijacking of the response to its tracker.
+// onHeader, if set, is called once just before the response headers are written.
+type streamWriter struct {
+	gin.ResponseWriter
+	tracker  *stream.Tracker
+	c        *gin.Context
+	onHeader func()
+}
+
+var _ contextWriter = &streamWriter{}
+
+func (w *streamWriter) requestContext() context.Context {
+	return w.c.Request.Context()
+}
+
+func (w *streamWriter) writingHeader() {
+	if w.onHeader == nil || w.ResponseWriter.Written() {
+		return
+	}
+	onHeader := w.onHeader
+	w.onHeader = nil
+	onHeader()
+}
+
+func (w *streamWriter) WriteHeaderNow() {
+	w.writingHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.writingHeader()
+	n, err := w.ResponseWriter.Write(p)
+	w.tracker.Wrote(n)
+	return n, err
+}
+
+func (w *streamWriter) WriteString(s string) (int, error) {
+	w.writingHeader()
+	n, err := w.ResponseWriter.WriteString(s)
+	w.tracker.Wrote(n)
+	return n, err
+}
+
+func (w *streamWriter) Flush() {
+	w.writingHeader()
+	w.ResponseWriter.Flush()
+	w.tracker.Flushed()
+}
+
+func (w *streamWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := w.ResponseWriter.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, rw = w.tracker.Hijacked(conn, rw)
+	return conn, rw, nil
+}
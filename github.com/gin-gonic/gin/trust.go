@@ -0,0 +1,58 @@
+package gin
+
+import (
+	"net"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UntrustedContext specifies what the OTel middleware does with the trace context
+// sent by a client it does not trust.
+type UntrustedContext int
+
+const (
+	// UntrustedContextLink starts a new trace whose root span links to the remote span context.
+	UntrustedContextLink UntrustedContext = iota
+	// UntrustedContextDrop starts a new trace and ignores the remote span context.
+	UntrustedContextDrop
+)
+
+// TrustFunc reports whether the trace context and baggage sent with a request are trusted.
+type TrustFunc func(c *gin.Context) bool
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// trusted reports whether the request of c comes from a trusted proxy or is accepted by
+// the trust function. Every request is trusted unless a trust policy is configured.
+func (cfg *config) trusted(c *gin.Context) bool {
+	if cfg.trustedNets == nil && cfg.trustFunc == nil {
+		return true
+	}
+	if cfg.trustFunc != nil && cfg.trustFunc(c) {
+		return true
+	}
+	host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		host = c.Request.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range cfg.trustedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
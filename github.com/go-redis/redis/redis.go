@@ -6,6 +6,8 @@ import (
 
 	"github.com/go-redis/redis/extra/rediscmd"
 	"github.com/go-redis/redis/v8"
+	"github.com/otel-contrib/instrumentation/internal/dependency"
+	"github.com/otel-contrib/instrumentation/internal/reqctx"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/semconv"
@@ -81,7 +83,7 @@ func NewOTelHook(opt *Options, opts ...Option) (Hook, error) {
 
 func (o *otelHook) BeforeProcess(ctx context.Context, cmd Cmder) (context.Context, error) {
 	start := time.Now()
-	ctx = context.WithValue(ctx, startTimeContextKey, start)
+	ctx = context.WithValue(reqctx.Unwrap(ctx), startTimeContextKey, start)
 
 	if !trace.SpanFromContext(ctx).IsRecording() {
 		return ctx, nil
@@ -117,15 +119,16 @@ func (o *otelHook) AfterProcess(ctx context.Context, cmd Cmder) error {
 	if !ok {
 		start = time.Now()
 	}
-	elapsedTime := time.Since(start).Milliseconds()
-	o.metricDuration.Record(ctx, elapsedTime)
+	elapsed := time.Since(start)
+	o.metricDuration.Record(ctx, elapsed.Milliseconds())
+	dependency.FromContext(ctx).Add(dependency.Cache, elapsed)
 
 	return nil
 }
 
 func (o *otelHook) BeforeProcessPipeline(ctx context.Context, cmds []Cmder) (context.Context, error) {
 	start := time.Now()
-	ctx = context.WithValue(ctx, startTimeContextKey, start)
+	ctx = context.WithValue(reqctx.Unwrap(ctx), startTimeContextKey, start)
 
 	if !trace.SpanFromContext(ctx).IsRecording() {
 		return ctx, nil
@@ -163,8 +166,9 @@ func (o *otelHook) AfterProcessPipeline(ctx context.Context, cmds []Cmder) error
 	if !ok {
 		start = time.Now()
 	}
-	elapsedTime := time.Since(start).Milliseconds()
-	o.metricDuration.Record(ctx, elapsedTime)
+	elapsed := time.Since(start)
+	o.metricDuration.Record(ctx, elapsed.Milliseconds())
+	dependency.FromContext(ctx).AddN(dependency.Cache, len(cmds), elapsed)
 
 	return nil
 }
@@ -3,6 +3,7 @@ package zap
 import (
 	"context"
 
+	"github.com/otel-contrib/instrumentation/internal/reqctx"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -101,6 +102,12 @@ func (log *Logger) With(fields ...Field) *Logger {
 	return &Logger{log: log.log.With(fields...)}
 }
 
+// WithContext creates a child logger that adds the trace and span IDs of the span
+// in ctx to every message.
+func (log *Logger) WithContext(ctx context.Context) *Logger {
+	return &Logger{log: log.log.With(fieldsFromContext(ctx)...)}
+}
+
 // Check returns a CheckedEntry if logging a message at the specified level
 // is enabled. It's a completely optional optimization; in high-performance
 // applications, Check can help avoid allocating a slice to hold fields.
@@ -222,7 +229,7 @@ func (log *Logger) Core() zapcore.Core {
 }
 
 func fieldsFromContext(ctx context.Context) []Field {
-	span := trace.SpanFromContext(ctx)
+	span := trace.SpanFromContext(reqctx.Unwrap(ctx))
 	if !span.IsRecording() {
 		return nil
 	}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/otel-contrib/instrumentation/internal/dependency"
+	"github.com/otel-contrib/instrumentation/internal/reqctx"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/label"
 	"go.opentelemetry.io/otel/metric"
@@ -166,8 +168,10 @@ func (o *otelPlugin) Initialize(db *DB) error {
 func (o *otelPlugin) before() func(*DB) {
 	return func(db *DB) {
 		start := time.Now()
-		ctx := context.WithValue(db.Statement.Context, startTimeContextKey, start)
+		ctx := context.WithValue(reqctx.Unwrap(db.Statement.Context), startTimeContextKey, start)
 		if !trace.SpanFromContext(ctx).IsRecording() {
+			// Keep the start time so that the query is still timed for the request.
+			db.Statement.Context = ctx
 			return
 		}
 
@@ -201,8 +205,9 @@ func (o *otelPlugin) after() func(*DB) {
 		if !ok {
 			start = time.Now()
 		}
-		elapsedTime := time.Since(start).Milliseconds()
-		o.metricDuration.Record(ctx, elapsedTime)
+		elapsed := time.Since(start)
+		o.metricDuration.Record(ctx, elapsed.Milliseconds())
+		dependency.FromContext(ctx).Add(dependency.DB, elapsed)
 
 		db.Statement.Context = ctx
 	}
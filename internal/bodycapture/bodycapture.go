@@ -0,0 +1,130 @@
+/*
+Package bodycapture records bounded copies of request and response bodies as span events.
+It is shared by the net/http and gin instrumentation.
+*/
+package bodycapture
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span events carrying a captured body.
+const (
+	EventRequestBody  = "http.request.body"
+	EventResponseBody = "http.response.body"
+)
+
+// Attribute keys of the body events.
+const (
+	LabelKeyBody          = label.Key("http.body")
+	LabelKeyBodyTruncated = label.Key("http.body.truncated")
+)
+
+// DefaultContentTypes are the content types captured unless others are configured.
+var DefaultContentTypes = []string{
+	"application/json",
+	"application/xml",
+	"application/x-www-form-urlencoded",
+	"text/*",
+}
+
+// Redactor rewrites a captured body before it is recorded, e.g. to mask secrets.
+type Redactor func(contentType string, body []byte) []byte
+
+// Config decides which bodies are captured and records them as span events.
+// Capture is disabled while MaxBytes is zero.
+type Config struct {
+	MaxBytes     int
+	ContentTypes []string
+	Redactor     Redactor
+}
+
+// Buffer retains the first bytes written to it and discards the rest.
+// It is safe for concurrent use, as the transport may still be writing
+// a request body while the response is being recorded.
+type Buffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n := b.max - b.buf.Len(); n < len(p) {
+		b.truncated = true
+		if n > 0 {
+			b.buf.Write(p[:n])
+		}
+		return len(p), nil
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
+
+// snapshot returns a copy of the bytes retained so far and whether any were discarded.
+func (b *Buffer) snapshot() ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...), b.truncated
+}
+
+// Enabled reports whether bodies are captured.
+func (c *Config) Enabled() bool {
+	return c.MaxBytes > 0
+}
+
+// NewBuffer returns a Buffer retaining up to MaxBytes.
+func (c *Config) NewBuffer() *Buffer {
+	return &Buffer{max: c.MaxBytes}
+}
+
+// Tee returns a body that records what is read from rc into a new Buffer.
+func (c *Config) Tee(rc io.ReadCloser) (io.ReadCloser, *Buffer) {
+	buf := c.NewBuffer()
+	return &teeReadCloser{Reader: io.TeeReader(rc, buf), Closer: rc}, buf
+}
+
+func (c *Config) captures(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range c.ContentTypes {
+		if t == mediaType {
+			return true
+		}
+		if strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// Record adds the body retained by buf to span as the given event, if its content type is captured.
+func (c *Config) Record(span trace.Span, event, contentType string, buf *Buffer) {
+	if buf == nil || !c.captures(contentType) {
+		return
+	}
+	body, truncated := buf.snapshot()
+	if c.Redactor != nil {
+		body = c.Redactor(contentType, body)
+	}
+	span.AddEvent(event, trace.WithAttributes(
+		LabelKeyBody.String(string(body)),
+		LabelKeyBodyTruncated.Bool(truncated),
+	))
+}
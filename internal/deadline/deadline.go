@@ -0,0 +1,44 @@
+/*
+Package deadline propagates the time left before the deadline of a request through a header.
+It is shared by the net/http and gin instrumentation.
+*/
+package deadline
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Inject sets the header name to the budget left before the deadline of ctx, in milliseconds.
+func Inject(ctx context.Context, name string, h http.Header) (int64, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	budget := time.Until(deadline).Milliseconds()
+	if budget < 0 {
+		budget = 0
+	}
+	h.Set(name, strconv.FormatInt(budget, 10))
+	return budget, true
+}
+
+// maxBudget is the largest budget, in milliseconds, a time.Duration can hold.
+const maxBudget = math.MaxInt64 / int64(time.Millisecond)
+
+// Extract returns the budget carried by the header name, as sent by Inject.
+// Negative budgets and budgets too large for a time.Duration are ignored.
+func Extract(name string, h http.Header) (time.Duration, bool) {
+	v := h.Get(name)
+	if v == "" {
+		return 0, false
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || ms < 0 || ms > maxBudget {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
@@ -0,0 +1,139 @@
+/*
+Package dependency accumulates the calls an inbound request makes to its dependencies,
+such as databases, caches and upstream HTTP services.
+
+The server instrumentation attaches a Recorder to the request context, and the client
+instrumentation of the same module adds every call it observes to it.
+*/
+package dependency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Kinds of dependencies reported by the client instrumentation.
+const (
+	DB       = "db"
+	Cache    = "cache"
+	Upstream = "upstream"
+)
+
+// Kinds lists the dependency kinds in the order they are reported.
+var Kinds = []string{DB, Cache, Upstream}
+
+// Attribute keys the calls of a request are rolled up into on its server span.
+const (
+	LabelKeyDBQueryCount            = label.Key("db.query_count")
+	LabelKeyDBTotalDuration         = label.Key("db.total_ms")
+	LabelKeyRedisCmdCount           = label.Key("redis.cmd_count")
+	LabelKeyRedisTotalDuration      = label.Key("redis.total_ms")
+	LabelKeyHTTPClientCallCount     = label.Key("http.client.call_count")
+	LabelKeyHTTPClientTotalDuration = label.Key("http.client.total_ms")
+	LabelKeyKind                    = label.Key("dependency.kind")
+)
+
+// attributes are the span attributes the calls of each kind are rolled up into.
+var attributes = map[string]struct {
+	count    label.Key
+	duration label.Key
+}{
+	DB:       {LabelKeyDBQueryCount, LabelKeyDBTotalDuration},
+	Cache:    {LabelKeyRedisCmdCount, LabelKeyRedisTotalDuration},
+	Upstream: {LabelKeyHTTPClientCallCount, LabelKeyHTTPClientTotalDuration},
+}
+
+type recorderType struct{}
+
+var recorderContextKey = &recorderType{}
+
+// Stats is the number of calls made to a kind of dependency and their total duration.
+type Stats struct {
+	Count    int64
+	Duration time.Duration
+}
+
+// Recorder accumulates the calls made while handling a single request.
+// It is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	stats map[string]Stats
+}
+
+// ContextWithRecorder returns a copy of ctx carrying a new Recorder.
+// If ctx already carries one, as when server instrumentation is nested, ctx and that Recorder
+// are returned so that every level accounts for the same calls.
+func ContextWithRecorder(ctx context.Context) (context.Context, *Recorder) {
+	if r := FromContext(ctx); r != nil {
+		return ctx, r
+	}
+	r := &Recorder{stats: make(map[string]Stats)}
+	return context.WithValue(ctx, recorderContextKey, r), r
+}
+
+// FromContext returns the Recorder carried by ctx, or nil if there is none.
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderContextKey).(*Recorder)
+	return r
+}
+
+// Add records a call of the given kind taking d. Calls on a nil Recorder are ignored.
+func (r *Recorder) Add(kind string, d time.Duration) {
+	r.AddN(kind, 1, d)
+}
+
+// AddN records n calls of the given kind made at once, such as the commands of a pipeline,
+// taking d altogether. Calls on a nil Recorder are ignored.
+func (r *Recorder) AddN(kind string, n int, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	s := r.stats[kind]
+	s.Count += int64(n)
+	s.Duration += d
+	r.stats[kind] = s
+	r.mu.Unlock()
+}
+
+// Stats returns the calls of the given kind recorded so far.
+func (r *Recorder) Stats(kind string) Stats {
+	if r == nil {
+		return Stats{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats[kind]
+}
+
+// Metrics are the per-route histograms the calls of a request are recorded to.
+type Metrics struct {
+	Calls    metric.Int64ValueRecorder
+	Duration metric.Int64ValueRecorder
+}
+
+// Record sets the number and total duration of the calls of each kind made while handling
+// the request on its server span, and records them to metrics labelled with metricLabels
+// and the kind. Kinds without calls are skipped.
+func (r *Recorder) Record(ctx context.Context, span trace.Span, metrics Metrics, metricLabels []label.KeyValue) {
+	for _, kind := range Kinds {
+		s := r.Stats(kind)
+		if s.Count == 0 {
+			continue
+		}
+		keys := attributes[kind]
+		span.SetAttributes(
+			keys.count.Int64(s.Count),
+			keys.duration.Int64(s.Duration.Milliseconds()),
+		)
+
+		labels := append(metricLabels[:len(metricLabels):len(metricLabels)], LabelKeyKind.String(kind))
+		metrics.Calls.Record(ctx, s.Count, labels...)
+		metrics.Duration.Record(ctx, s.Duration.Milliseconds(), labels...)
+	}
+}
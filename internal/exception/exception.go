@@ -0,0 +1,80 @@
+/*
+Package exception records recovered panics on the span of the request they occurred in.
+It is shared by the net/http and gin instrumentation.
+
+The server instrumentation attaches a Panic to the request context, which the recovery
+middleware marks, so that the span status the server sets from the response status code
+does not override the Error status of a panic recovered after the response was written.
+*/
+package exception
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Event is the name of the span event describing a recovered panic.
+const Event = "exception"
+
+// Attribute keys of the exception event.
+const (
+	LabelKeyType       = label.Key("exception.type")
+	LabelKeyMessage    = label.Key("exception.message")
+	LabelKeyStacktrace = label.Key("exception.stacktrace")
+)
+
+type panicType struct{}
+
+var panicContextKey = &panicType{}
+
+// Panic records the panic recovered while serving a single request, if any.
+// It is safe for concurrent use.
+type Panic struct {
+	mu        sync.Mutex
+	recovered bool
+	msg       string
+}
+
+// ContextWithPanic returns a copy of ctx carrying a new Panic.
+// If ctx already carries one, as when server instrumentation is nested, ctx and that Panic are returned.
+func ContextWithPanic(ctx context.Context) (context.Context, *Panic) {
+	if p, ok := ctx.Value(panicContextKey).(*Panic); ok {
+		return ctx, p
+	}
+	p := &Panic{}
+	return context.WithValue(ctx, panicContextKey, p), p
+}
+
+// Record adds an exception event for r to span, marks it as Error,
+// and marks the Panic carried by ctx, if any, as recovered.
+func Record(ctx context.Context, span trace.Span, r interface{}, stack []byte) {
+	msg := "panic: " + fmt.Sprint(r)
+	span.AddEvent(Event, trace.WithAttributes(
+		LabelKeyType.String(fmt.Sprintf("%T", r)),
+		LabelKeyMessage.String(fmt.Sprint(r)),
+		LabelKeyStacktrace.String(string(stack)),
+	))
+	span.SetStatus(codes.Error, msg)
+
+	if p, ok := ctx.Value(panicContextKey).(*Panic); ok {
+		p.mu.Lock()
+		p.recovered = true
+		p.msg = msg
+		p.mu.Unlock()
+	}
+}
+
+// SetStatus sets the status of span to Error if a panic was recovered.
+// It is called after the status has been set from the response.
+func (p *Panic) SetStatus(span trace.Span) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.recovered {
+		span.SetStatus(codes.Error, p.msg)
+	}
+}
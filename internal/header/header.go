@@ -0,0 +1,83 @@
+/*
+Package header records allowlisted request and response headers as span attributes,
+masking the values of sensitive headers. It is shared by the net/http and gin instrumentation.
+*/
+package header
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/label"
+)
+
+// Prefixes of the captured header attributes.
+const (
+	RequestPrefix  = "http.request.header"
+	ResponsePrefix = "http.response.header"
+)
+
+const redactedValue = "[REDACTED]"
+
+// DefaultSensitive are the headers always masked unless explicitly unmasked.
+var DefaultSensitive = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// CanonicalKeys returns the canonical format of the given header keys.
+func CanonicalKeys(keys []string) []string {
+	canonical := make([]string, 0, len(keys))
+	for _, key := range keys {
+		canonical = append(canonical, http.CanonicalHeaderKey(key))
+	}
+	return canonical
+}
+
+// Set is a set of canonical header keys.
+type Set map[string]struct{}
+
+// NewSet returns a Set holding the given keys.
+func NewSet(keys []string) Set {
+	s := make(Set, len(keys))
+	s.Add(keys...)
+	return s
+}
+
+// Add adds the given keys to s.
+func (s Set) Add(keys ...string) {
+	for _, key := range CanonicalKeys(keys) {
+		s[key] = struct{}{}
+	}
+}
+
+// Remove removes the given keys from s.
+func (s Set) Remove(keys ...string) {
+	for _, key := range CanonicalKeys(keys) {
+		delete(s, key)
+	}
+}
+
+// Attributes returns the captured headers as attributes named <prefix>.<name>,
+// e.g. http.request.header.x_tenant, with the values of sensitive headers masked.
+func Attributes(prefix string, h http.Header, captured []string, sensitive Set) []label.KeyValue {
+	var attrs []label.KeyValue
+	for _, key := range captured {
+		values, ok := h[key]
+		if !ok {
+			continue
+		}
+		if _, ok := sensitive[key]; ok {
+			masked := make([]string, len(values))
+			for i := range masked {
+				masked[i] = redactedValue
+			}
+			values = masked
+		}
+		name := prefix + "." + strings.ReplaceAll(strings.ToLower(key), "-", "_")
+		attrs = append(attrs, label.Array(name, values))
+	}
+	return attrs
+}
@@ -0,0 +1,48 @@
+/*
+Package reqctx lets the client instrumentation find the span of a request served by gin
+when it is given the *gin.Context instead of the request context.
+
+A *gin.Context used as a context.Context only resolves string keys, looked up among the keys
+set on it, so the gin middleware sets Key to a function returning the current request context.
+*/
+package reqctx
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Key is the *gin.Context key the gin middleware sets to a Func. It is a string because
+// gin.Context resolves no other key type.
+const Key = "github.com/otel-contrib/instrumentation/request-context"
+
+// Func returns the current context of the request.
+type Func func() context.Context
+
+// Unwrap returns ctx if it carries a span itself. Otherwise, if ctx leads to a request context
+// through Key, as a *gin.Context and the contexts derived from it do, Unwrap returns a context
+// keeping the deadline, cancellation and values of ctx, which falls back to the request context
+// for the values ctx lacks, such as the span, the baggage and the dependency recorder.
+func Unwrap(ctx context.Context) context.Context {
+	if trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return ctx
+	}
+	if f, ok := ctx.Value(Key).(Func); ok {
+		return &mergedContext{Context: ctx, values: f()}
+	}
+	return ctx
+}
+
+// mergedContext is a context whose values are looked up in values when the embedded context lacks them.
+type mergedContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c *mergedContext) Value(key interface{}) interface{} {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.values.Value(key)
+}
@@ -0,0 +1,63 @@
+/*
+Package servertiming sets the W3C traceresponse and Server-Timing headers of server responses.
+It is shared by the net/http and gin server instrumentation.
+*/
+package servertiming
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/otel-contrib/instrumentation/internal/dependency"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	headerTraceResponse = "traceresponse"
+	headerServerTiming  = "Server-Timing"
+	metricTotal         = "total"
+)
+
+// Headers decides which of the traceresponse and Server-Timing headers are sent.
+type Headers struct {
+	TraceResponse bool
+	ServerTiming  bool
+}
+
+// Enabled reports whether any header is sent.
+func (hs Headers) Enabled() bool {
+	return hs.TraceResponse || hs.ServerTiming
+}
+
+// Set sets the headers on h just before they are written. The total is the time the handler
+// has taken so far, and the dependency timings cover the calls that have completed by then.
+func (hs Headers) Set(h http.Header, span trace.Span, start time.Time, deps *dependency.Recorder) {
+	if hs.TraceResponse {
+		if sc := span.SpanContext(); sc.IsValid() {
+			h.Set(headerTraceResponse, fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.TraceFlags))
+		}
+	}
+	if hs.ServerTiming {
+		h.Add(headerServerTiming, value(time.Since(start), deps))
+	}
+}
+
+// value formats the total handler time and the time spent per kind of dependency
+// as a Server-Timing header value, e.g. "total;dur=12.5, db;dur=3.2".
+func value(total time.Duration, deps *dependency.Recorder) string {
+	metrics := []string{metric(metricTotal, total)}
+	for _, kind := range dependency.Kinds {
+		if s := deps.Stats(kind); s.Count > 0 {
+			metrics = append(metrics, metric(kind, s.Duration))
+		}
+	}
+	return strings.Join(metrics, ", ")
+}
+
+func metric(name string, d time.Duration) string {
+	ms := float64(d.Round(time.Microsecond)) / float64(time.Millisecond)
+	return name + ";dur=" + strconv.FormatFloat(ms, 'f', -1, 64)
+}
@@ -0,0 +1,265 @@
+/*
+Package stream follows the server responses that outlive the handler's first write:
+streamed responses, detected by their first flush, and hijacked connections, such as WebSockets.
+It is shared by the net/http and gin server instrumentation.
+*/
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys set on the server span of a streamed response or hijacked connection.
+const (
+	LabelKeyKind            = label.Key("http.stream.kind")
+	LabelKeyMessages        = label.Key("http.stream.messages")
+	LabelKeyBytes           = label.Key("http.stream.bytes")
+	LabelKeyCloseReason     = label.Key("http.stream.close_reason")
+	LabelKeyTimeToFirstByte = label.Key("http.time_to_first_byte_ms")
+)
+
+const (
+	kindStream   = "stream"
+	kindHijacked = "hijacked"
+
+	closeReasonClient = "client_closed"
+	closeReasonServer = "server_closed"
+	closeReasonError  = "error"
+)
+
+// Metrics are the instruments a Tracker records to.
+type Metrics struct {
+	TimeToFirstByte metric.Int64ValueRecorder
+	MessageCount    metric.Int64Counter
+	Bytes           metric.Int64Counter
+}
+
+// Tracker follows the response to a single request. The span of a hijacked connection
+// ends once both the handler has returned and the connection is closed.
+// The methods reporting writes may be called on a nil Tracker.
+type Tracker struct {
+	ctx          context.Context
+	span         trace.Span
+	start        time.Time
+	metricLabels []label.KeyValue
+	metrics      Metrics
+
+	mu          sync.Mutex
+	kind        string
+	firstByte   time.Duration
+	messages    int64
+	bytes       int64
+	unflushed   int64
+	closeReason string
+	pending     int
+}
+
+// NewTracker returns a Tracker ending span once the response is over.
+func NewTracker(ctx context.Context, span trace.Span, start time.Time, metricLabels []label.KeyValue, metrics Metrics) *Tracker {
+	return &Tracker{
+		ctx:          ctx,
+		span:         span,
+		start:        start,
+		metricLabels: metricLabels,
+		metrics:      metrics,
+		pending:      1,
+	}
+}
+
+func (t *Tracker) labels() []label.KeyValue {
+	return append(t.metricLabels[:len(t.metricLabels):len(t.metricLabels)], LabelKeyKind.String(t.kind))
+}
+
+// Wrote is called for every write to the response.
+func (t *Tracker) Wrote(n int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.firstByte == 0 {
+		t.firstByte = time.Since(t.start)
+	}
+	t.bytes += int64(n)
+	t.unflushed += int64(n)
+}
+
+// Flushed is called for every flush of the response, each counted as a message of the stream.
+func (t *Tracker) Flushed() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.kind == "" {
+		t.kind = kindStream
+	}
+	if t.kind != kindStream {
+		return
+	}
+	t.messages++
+	t.metrics.MessageCount.Add(t.ctx, 1, t.labels()...)
+	t.metrics.Bytes.Add(t.ctx, t.unflushed, t.labels()...)
+	t.unflushed = 0
+}
+
+// Hijacked is called once the connection has been hijacked and returns the connection and
+// buffered reader and writer to hand to the handler, both going through the tracked connection.
+// Bytes already buffered by brw are read first.
+func (t *Tracker) Hijacked(conn net.Conn, brw *bufio.ReadWriter) (net.Conn, *bufio.ReadWriter) {
+	if t == nil {
+		return conn, brw
+	}
+	t.mu.Lock()
+	t.kind = kindHijacked
+	t.pending++
+	t.mu.Unlock()
+
+	tc := &trackedConn{Conn: conn, tracker: t}
+	if brw == nil {
+		return tc, nil
+	}
+
+	var r io.Reader = tc
+	if n := brw.Reader.Buffered(); n > 0 {
+		buffered, _ := brw.Reader.Peek(n)
+		r = io.MultiReader(bytes.NewReader(append([]byte(nil), buffered...)), tc)
+	}
+	// Anything still buffered for writing goes out first, on the connection it was meant for.
+	_ = brw.Writer.Flush()
+
+	return tc, bufio.NewReadWriter(
+		bufio.NewReaderSize(r, brw.Reader.Size()),
+		bufio.NewWriterSize(tc, brw.Writer.Size()),
+	)
+}
+
+// connWrote is called for every write to a hijacked connection, each counted as a message.
+func (t *Tracker) connWrote(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.firstByte == 0 {
+		t.firstByte = time.Since(t.start)
+	}
+	t.messages++
+	t.bytes += int64(n)
+	t.metrics.MessageCount.Add(t.ctx, 1, t.labels()...)
+	t.metrics.Bytes.Add(t.ctx, int64(n), t.labels()...)
+}
+
+// Streaming reports whether the response is streamed or the connection hijacked.
+func (t *Tracker) Streaming() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.kind != ""
+}
+
+// IsHijacked reports whether the connection has been hijacked.
+func (t *Tracker) IsHijacked() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.kind == kindHijacked
+}
+
+// HandlerDone is called once the handler has returned.
+func (t *Tracker) HandlerDone(ctx context.Context) {
+	t.mu.Lock()
+	if t.kind == kindStream {
+		t.closeReason = closeReasonServer
+		if errors.Is(ctx.Err(), context.Canceled) {
+			t.closeReason = closeReasonClient
+		}
+	}
+	t.mu.Unlock()
+	t.release()
+}
+
+// connClosed is called once a hijacked connection is closed, after reading from it failed with readErr, if any.
+func (t *Tracker) connClosed(readErr error) {
+	t.mu.Lock()
+	switch {
+	case readErr == nil:
+		t.closeReason = closeReasonServer
+	case errors.Is(readErr, io.EOF):
+		t.closeReason = closeReasonClient
+	default:
+		t.closeReason = closeReasonError
+	}
+	t.mu.Unlock()
+	t.release()
+}
+
+func (t *Tracker) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending--
+	if t.pending > 0 {
+		return
+	}
+
+	if t.kind != "" {
+		t.span.SetAttributes(
+			LabelKeyKind.String(t.kind),
+			LabelKeyMessages.Int64(t.messages),
+			LabelKeyBytes.Int64(t.bytes),
+			LabelKeyCloseReason.String(t.closeReason),
+		)
+		if t.firstByte > 0 {
+			t.span.SetAttributes(LabelKeyTimeToFirstByte.Int64(t.firstByte.Milliseconds()))
+			t.metrics.TimeToFirstByte.Record(t.ctx, t.firstByte.Milliseconds(), t.labels()...)
+		}
+	}
+	t.span.End()
+}
+
+// trackedConn is a hijacked connection reporting its writes and closing to its tracker.
+type trackedConn struct {
+	net.Conn
+	tracker *Tracker
+
+	mu      sync.Mutex
+	readErr error
+	once    sync.Once
+}
+
+func (c *trackedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if err != nil {
+		c.mu.Lock()
+		if c.readErr == nil {
+			c.readErr = err
+		}
+		c.mu.Unlock()
+	}
+	return n, err
+}
+
+func (c *trackedConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.tracker.connWrote(n)
+	}
+	return n, err
+}
+
+func (c *trackedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() {
+		c.mu.Lock()
+		readErr := c.readErr
+		c.mu.Unlock()
+		c.tracker.connClosed(readErr)
+	})
+	return err
+}
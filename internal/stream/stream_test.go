@@ -0,0 +1,206 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func newTestTracker(ctx context.Context) (*Tracker, *oteltest.Span) {
+	tp := oteltest.NewTracerProvider()
+	_, span := tp.Tracer("test").Start(ctx, "server")
+	meter := metric.Must(metric.NoopMeterProvider{}.Meter("test"))
+	tracker := NewTracker(ctx, span, time.Now(), nil, Metrics{
+		TimeToFirstByte: meter.NewInt64ValueRecorder("ttfb"),
+		MessageCount:    meter.NewInt64Counter("messages"),
+		Bytes:           meter.NewInt64Counter("bytes"),
+	})
+	return tracker, span.(*oteltest.Span)
+}
+
+func TestTrackerNotStreaming(t *testing.T) {
+	tracker, span := newTestTracker(context.Background())
+	tracker.Wrote(4)
+
+	if tracker.Streaming() {
+		t.Fatal("Streaming() = true before any flush")
+	}
+	tracker.HandlerDone(context.Background())
+
+	if !span.Ended() {
+		t.Fatal("span not ended once the handler returned")
+	}
+	if _, ok := span.Attributes()[LabelKeyKind]; ok {
+		t.Errorf("unexpected %s attribute on a plain response", LabelKeyKind)
+	}
+}
+
+func TestTrackerStream(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		closeReason string
+	}{
+		{name: "server closed", ctx: context.Background(), closeReason: closeReasonServer},
+		{name: "client closed", ctx: canceled, closeReason: closeReasonClient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, span := newTestTracker(context.Background())
+			tracker.Wrote(5)
+			tracker.Flushed()
+			tracker.Wrote(3)
+			tracker.Flushed()
+
+			if !tracker.Streaming() || tracker.IsHijacked() {
+				t.Fatalf("Streaming() = %v, IsHijacked() = %v, want true, false", tracker.Streaming(), tracker.IsHijacked())
+			}
+			if span.Ended() {
+				t.Fatal("span ended before the handler returned")
+			}
+			tracker.HandlerDone(tt.ctx)
+
+			if !span.Ended() {
+				t.Fatal("span not ended once the handler returned")
+			}
+			attrs := span.Attributes()
+			if got := attrs[LabelKeyKind].AsString(); got != kindStream {
+				t.Errorf("%s = %q, want %q", LabelKeyKind, got, kindStream)
+			}
+			if got := attrs[LabelKeyMessages].AsInt64(); got != 2 {
+				t.Errorf("%s = %d, want 2", LabelKeyMessages, got)
+			}
+			if got := attrs[LabelKeyBytes].AsInt64(); got != 8 {
+				t.Errorf("%s = %d, want 8", LabelKeyBytes, got)
+			}
+			if got := attrs[LabelKeyCloseReason].AsString(); got != tt.closeReason {
+				t.Errorf("%s = %q, want %q", LabelKeyCloseReason, got, tt.closeReason)
+			}
+		})
+	}
+}
+
+func TestTrackerHijacked(t *testing.T) {
+	tests := []struct {
+		name         string
+		handlerFirst bool
+		peerCloses   bool
+		closeReason  string
+	}{
+		{name: "handler returns first", handlerFirst: true, closeReason: closeReasonServer},
+		{name: "connection closed first", closeReason: closeReasonServer},
+		{name: "peer closes", handlerFirst: true, peerCloses: true, closeReason: closeReasonClient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, span := newTestTracker(context.Background())
+			server, client := net.Pipe()
+			defer client.Close()
+
+			brw := bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server))
+			conn, rw := tracker.Hijacked(server, brw)
+			if !tracker.IsHijacked() {
+				t.Fatal("IsHijacked() = false after Hijacked")
+			}
+
+			received := make(chan string)
+			go func() {
+				b := make([]byte, 5)
+				_, _ = io.ReadFull(client, b)
+				received <- string(b)
+			}()
+			if _, err := rw.WriteString("hello"); err != nil {
+				t.Fatal(err)
+			}
+			if err := rw.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if got := <-received; got != "hello" {
+				t.Fatalf("peer received %q, want %q", got, "hello")
+			}
+
+			if tt.peerCloses {
+				client.Close()
+				if _, err := rw.ReadByte(); err != io.EOF {
+					t.Fatalf("read after the peer closed: %v, want EOF", err)
+				}
+			}
+
+			first, second := func() { tracker.HandlerDone(context.Background()) }, func() { conn.Close() }
+			if !tt.handlerFirst {
+				first, second = second, first
+			}
+			first()
+			if span.Ended() {
+				t.Fatal("span ended while the connection or the handler was still pending")
+			}
+			second()
+			if !span.Ended() {
+				t.Fatal("span not ended once both the handler returned and the connection closed")
+			}
+
+			attrs := span.Attributes()
+			if got := attrs[LabelKeyKind].AsString(); got != kindHijacked {
+				t.Errorf("%s = %q, want %q", LabelKeyKind, got, kindHijacked)
+			}
+			if got := attrs[LabelKeyMessages].AsInt64(); got != 1 {
+				t.Errorf("%s = %d, want 1", LabelKeyMessages, got)
+			}
+			if got := attrs[LabelKeyBytes].AsInt64(); got != 5 {
+				t.Errorf("%s = %d, want 5", LabelKeyBytes, got)
+			}
+			if got := attrs[LabelKeyCloseReason].AsString(); got != tt.closeReason {
+				t.Errorf("%s = %q, want %q", LabelKeyCloseReason, got, tt.closeReason)
+			}
+		})
+	}
+}
+
+func TestTrackerHijackedKeepsBufferedBytes(t *testing.T) {
+	tracker, _ := newTestTracker(context.Background())
+	server, client := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		_, _ = client.Write([]byte("early"))
+		_, _ = client.Write([]byte("late"))
+	}()
+	brw := bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server))
+	if _, err := brw.Reader.Peek(5); err != nil {
+		t.Fatal(err)
+	}
+
+	_, rw := tracker.Hijacked(server, brw)
+	b := make([]byte, 9)
+	if _, err := io.ReadFull(rw, b); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != "earlylate" {
+		t.Errorf("read %q, want %q", got, "earlylate")
+	}
+}
+
+func TestNilTracker(t *testing.T) {
+	var tracker *Tracker
+	tracker.Wrote(1)
+	tracker.Flushed()
+
+	server, client := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	brw := bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server))
+	conn, rw := tracker.Hijacked(server, brw)
+	if conn != server || rw != brw {
+		t.Error("Hijacked on a nil Tracker did not return the connection as is")
+	}
+}
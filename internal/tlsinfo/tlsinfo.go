@@ -0,0 +1,59 @@
+/*
+Package tlsinfo describes negotiated TLS connections as span attributes.
+It is shared by the net/http and gin instrumentation.
+*/
+package tlsinfo
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"go.opentelemetry.io/otel/label"
+)
+
+// Attribute keys describing a TLS connection.
+const (
+	LabelKeyVersion      = label.Key("tls.version")
+	LabelKeyCipherSuite  = label.Key("tls.cipher_suite")
+	LabelKeyALPNProtocol = label.Key("tls.alpn_protocol")
+	LabelKeyServerName   = label.Key("tls.server_name")
+	LabelKeyPeerSubject  = label.Key("tls.peer.subject")
+	LabelKeyPeerIssuer   = label.Key("tls.peer.issuer")
+)
+
+// Attributes returns the attributes describing the negotiated TLS connection.
+func Attributes(state *tls.ConnectionState) []label.KeyValue {
+	attrs := []label.KeyValue{
+		LabelKeyVersion.String(versionName(state.Version)),
+		LabelKeyCipherSuite.String(tls.CipherSuiteName(state.CipherSuite)),
+	}
+	if state.NegotiatedProtocol != "" {
+		attrs = append(attrs, LabelKeyALPNProtocol.String(state.NegotiatedProtocol))
+	}
+	if state.ServerName != "" {
+		attrs = append(attrs, LabelKeyServerName.String(state.ServerName))
+	}
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		attrs = append(attrs,
+			LabelKeyPeerSubject.String(cert.Subject.String()),
+			LabelKeyPeerIssuer.String(cert.Issuer.String()),
+		)
+	}
+	return attrs
+}
+
+func versionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "1.0"
+	case tls.VersionTLS11:
+		return "1.1"
+	case tls.VersionTLS12:
+		return "1.2"
+	case tls.VersionTLS13:
+		return "1.3"
+	default:
+		return fmt.Sprintf("0x%04x", version)
+	}
+}
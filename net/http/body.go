@@ -0,0 +1,6 @@
+package http
+
+import "github.com/otel-contrib/instrumentation/internal/bodycapture"
+
+// BodyRedactor rewrites a captured body before it is recorded, e.g. to mask secrets.
+type BodyRedactor = bodycapture.Redactor
@@ -15,28 +15,36 @@ import (
 type Client = http.Client
 
 // DefaultClient is the default Client and is used by Get, Head, and Post.
-var DefaultClient = mustClient(NewClient(http.DefaultClient))
+// It is built from a copy of http.DefaultClient, which is left untouched.
+var DefaultClient = newDefaultClient()
 
 // RoundTripper is an interface representing the ability to execute a single HTTP transaction,
 // obtaining the Response for a given Request.
 type RoundTripper = http.RoundTripper
 
 // NewClient returns a client that provides OpenTelemetry tracing and metrics.
+// Every request is traced as a logical request span with one child span per hop,
+// the redirects followed included, and the client's CheckRedirect policy, if any, is still honoured.
+// The Transport and CheckRedirect fields of c are replaced.
 func NewClient(c *Client, opts ...Option) (*Client, error) {
+	opts = append(opts[:len(opts):len(opts)], withRedirectChain())
 	transport, err := NewOTelTransport(c.Transport, opts...)
 	if err != nil {
 		return c, err
 	}
 	c.Transport = transport
+	c.CheckRedirect = checkRedirect(c.CheckRedirect)
 
 	return c, nil
 }
 
-func mustClient(c *Client, err error) *Client {
+func newDefaultClient() *Client {
+	c := *http.DefaultClient
+	client, err := NewClient(&c)
 	if err != nil {
 		panic(err)
 	}
-	return c
+	return client
 }
 
 // Get issues a GET to the specified URL.
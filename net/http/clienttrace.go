@@ -0,0 +1,182 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	phaseGetConn   = "getconn"
+	phaseDNS       = "dns"
+	phaseConnect   = "connect"
+	phaseTLS       = "tls"
+	phaseFirstByte = "first_byte"
+)
+
+// clientTracer records the httptrace phases of a single round trip as child spans.
+type clientTracer struct {
+	ctx           context.Context
+	tracer        trace.Tracer
+	operationName string
+	labels        []label.KeyValue
+	metrics       map[string]metric.Int64ValueRecorder
+
+	mu     sync.Mutex
+	phases map[string]*phaseSpan
+	done   bool
+}
+
+type phaseSpan struct {
+	phase string
+	start time.Time
+	span  trace.Span
+}
+
+func newClientTracer(ctx context.Context, o *otelTransport, labels []label.KeyValue) *clientTracer {
+	return &clientTracer{
+		ctx:           ctx,
+		tracer:        o.tracer,
+		operationName: o.operationName,
+		labels:        labels,
+		// The getconn phase is recorded per host by connPoolTrace.
+		metrics: map[string]metric.Int64ValueRecorder{
+			phaseDNS:       o.metricDNSDuration,
+			phaseConnect:   o.metricConnectDuration,
+			phaseTLS:       o.metricTLSDuration,
+			phaseFirstByte: o.metricFirstByteDuration,
+		},
+		phases: make(map[string]*phaseSpan),
+	}
+}
+
+func (ct *clientTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			ct.start(phaseGetConn, phaseGetConn)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			attrs := []label.KeyValue{
+				LabelKeyHTTPConnReused.Bool(info.Reused),
+				LabelKeyHTTPConnWasIdle.Bool(info.WasIdle),
+			}
+			if info.WasIdle {
+				attrs = append(attrs, LabelKeyHTTPConnIdleTime.Int64(info.IdleTime.Milliseconds()))
+			}
+			if info.Conn != nil {
+				attrs = append(attrs, semconv.NetPeerIPKey.String(hostFromAddr(info.Conn.RemoteAddr().String())))
+			}
+			trace.SpanFromContext(ct.ctx).SetAttributes(attrs...)
+			ct.end(phaseGetConn, nil, attrs...)
+		},
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			ct.start(phaseDNS, phaseDNS, semconv.NetPeerNameKey.String(info.Host))
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			ct.end(phaseDNS, info.Err)
+		},
+		ConnectStart: func(network, addr string) {
+			ct.start(phaseConnect+":"+addr, phaseConnect,
+				semconv.NetTransportKey.String(network),
+				semconv.NetPeerIPKey.String(hostFromAddr(addr)),
+			)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			ct.end(phaseConnect+":"+addr, err)
+		},
+		TLSHandshakeStart: func() {
+			ct.start(phaseTLS, phaseTLS)
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			ct.end(phaseTLS, err)
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			if info.Err != nil {
+				trace.SpanFromContext(ct.ctx).RecordError(info.Err)
+				return
+			}
+			ct.start(phaseFirstByte, phaseFirstByte)
+		},
+		GotFirstResponseByte: func() {
+			ct.end(phaseFirstByte, nil)
+		},
+	}
+}
+
+func (ct *clientTracer) start(key, phase string, attrs ...label.KeyValue) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if ct.done {
+		return
+	}
+	// A phase started again, e.g. when the transport retries the request on a new connection,
+	// ends the span still open for it, which is left out of the metrics.
+	if p, ok := ct.phases[key]; ok {
+		p.span.End()
+	}
+
+	// DNS, connect and TLS happen while a connection is being acquired,
+	// so they are parented to the getconn span when there is one.
+	ctx := ct.ctx
+	if p, ok := ct.phases[phaseGetConn]; ok && phase != phaseGetConn {
+		ctx = trace.ContextWithSpan(ctx, p.span)
+	}
+	_, span := ct.tracer.Start(ctx, ct.operationName+"."+phase,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+	)
+	ct.phases[key] = &phaseSpan{phase: phase, start: time.Now(), span: span}
+}
+
+func (ct *clientTracer) end(key string, err error, attrs ...label.KeyValue) {
+	ct.mu.Lock()
+	p, ok := ct.phases[key]
+	delete(ct.phases, key)
+	ct.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	p.span.SetAttributes(attrs...)
+	if err != nil {
+		p.span.RecordError(err)
+		p.span.SetStatus(codes.Error, err.Error())
+	}
+	p.span.End()
+
+	if m, ok := ct.metrics[p.phase]; ok {
+		m.Record(ct.ctx, time.Since(p.start).Milliseconds(), ct.labels...)
+	}
+}
+
+// endAll ends the phases that never completed, e.g. because the round trip failed.
+func (ct *clientTracer) endAll() {
+	ct.mu.Lock()
+	ct.done = true
+	keys := make([]string, 0, len(ct.phases))
+	for key := range ct.phases {
+		keys = append(keys, key)
+	}
+	ct.mu.Unlock()
+
+	for _, key := range keys {
+		ct.end(key, nil)
+	}
+}
+
+func hostFromAddr(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
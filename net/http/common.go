@@ -1,13 +1,96 @@
 package http
 
+import (
+	"time"
+
+	"github.com/otel-contrib/instrumentation/internal/bodycapture"
+	"github.com/otel-contrib/instrumentation/internal/dependency"
+	"github.com/otel-contrib/instrumentation/internal/exception"
+	"github.com/otel-contrib/instrumentation/internal/stream"
+	"github.com/otel-contrib/instrumentation/internal/tlsinfo"
+	"go.opentelemetry.io/otel/label"
+)
+
 const (
 	defaultInstrumentationName = "github.com/otel-contrib/instrumentation/net/http"
+	defaultServerName          = "http"
 	defaultOperationName       = "http"
+	defaultMaxRetries          = 3
+	defaultMaxRetryWait        = 5 * time.Second
+)
+
+// Semantic conventions for attribute keys for http.
+const (
+	LabelKeyHTTPServerInFlightRequests = label.Key("http.server.in_flight_requests")
+	LabelKeyHTTPServerDrainedRequests  = label.Key("http.server.drained_requests")
+	LabelKeyHTTPConnReused             = label.Key("http.conn.reused")
+	LabelKeyHTTPConnWasIdle            = label.Key("http.conn.was_idle")
+	LabelKeyHTTPConnIdleTime           = label.Key("http.conn.idle_time") // milliseconds
+	LabelKeyHTTPRetryCount             = label.Key("http.retry_count")
+	LabelKeyHTTPRedirectCount          = label.Key("http.redirect_count")
+	LabelKeyHTTPRedirectHop            = label.Key("http.redirect.hop")
+	LabelKeyHTTPRedirectLocation       = label.Key("http.redirect.location")
+	LabelKeyHTTPPropagationSuppressed  = label.Key("http.propagation.suppressed")
+	LabelKeyHTTPDeadlineBudget         = label.Key("http.deadline_budget_ms")
+	LabelKeyExceptionType              = exception.LabelKeyType
+	LabelKeyExceptionMessage           = exception.LabelKeyMessage
+	LabelKeyExceptionStacktrace        = exception.LabelKeyStacktrace
+	LabelKeyHTTPStreamKind             = stream.LabelKeyKind
+	LabelKeyHTTPStreamMessages         = stream.LabelKeyMessages
+	LabelKeyHTTPStreamBytes            = stream.LabelKeyBytes
+	LabelKeyHTTPStreamCloseReason      = stream.LabelKeyCloseReason
+	LabelKeyHTTPTimeToFirstByte        = stream.LabelKeyTimeToFirstByte
+	LabelKeyHTTPProxyUpstream          = label.Key("http.proxy.upstream")
+	LabelKeyTLSVersion                 = tlsinfo.LabelKeyVersion
+	LabelKeyTLSCipherSuite             = tlsinfo.LabelKeyCipherSuite
+	LabelKeyTLSALPNProtocol            = tlsinfo.LabelKeyALPNProtocol
+	LabelKeyTLSServerName              = tlsinfo.LabelKeyServerName
+	LabelKeyTLSPeerSubject             = tlsinfo.LabelKeyPeerSubject
+	LabelKeyTLSPeerIssuer              = tlsinfo.LabelKeyPeerIssuer
+	LabelKeyHTTPBody                   = bodycapture.LabelKeyBody
+	LabelKeyHTTPBodyTruncated          = bodycapture.LabelKeyBodyTruncated
+	LabelKeyDBQueryCount               = dependency.LabelKeyDBQueryCount
+	LabelKeyDBTotalDuration            = dependency.LabelKeyDBTotalDuration
+	LabelKeyRedisCmdCount              = dependency.LabelKeyRedisCmdCount
+	LabelKeyRedisTotalDuration         = dependency.LabelKeyRedisTotalDuration
+	LabelKeyHTTPClientCallCount        = dependency.LabelKeyHTTPClientCallCount
+	LabelKeyHTTPClientTotalDuration    = dependency.LabelKeyHTTPClientTotalDuration
+	LabelKeyDependencyKind             = dependency.LabelKeyKind
 )
 
 // Metrics semantic conventions
 const (
-	metricHTTPClientDuration           = "http.client.duration"             // process time, milliseconds
-	metricHTTPClientRequestCount       = "http.client.request_count"        // incoming request count total
-	metricHTTPClientRequestFailedCount = "http.client.request_failed_count" // incoming request failed count total
+	metricHTTPClientDuration                 = "http.client.duration"                    // process time, milliseconds
+	metricHTTPClientRequestCount             = "http.client.request_count"               // incoming request count total
+	metricHTTPClientRequestFailedCount       = "http.client.request_failed_count"        // incoming request failed count total
+	metricHTTPClientActiveRequests           = "http.client.active_requests"             // in-flight requests
+	metricHTTPClientConnCount                = "http.client.connection_count"            // obtained connection count total, labelled by reuse
+	metricHTTPClientGetConnDuration          = "http.client.get_conn_duration"           // connection pool acquisition time, milliseconds
+	metricHTTPClientDNSDuration              = "http.client.dns_duration"                // DNS lookup time, milliseconds
+	metricHTTPClientConnectDuration          = "http.client.connect_duration"            // TCP connect time, milliseconds
+	metricHTTPClientTLSDuration              = "http.client.tls_duration"                // TLS handshake time, milliseconds
+	metricHTTPClientFirstByteDuration        = "http.client.first_byte_duration"         // time waiting for the first response byte, milliseconds
+	metricHTTPClientRequestSize              = "http.client.request_size"                // request body size, bytes
+	metricHTTPClientResponseSize             = "http.client.response_size"               // response body size, bytes
+	metricHTTPClientRetryCount               = "http.client.retry_count"                 // retried attempt count total
+	metricHTTPClientRetryGiveUpCount         = "http.client.retry_give_up_count"         // requests that exhausted their retries count total
+	metricHTTPClientSuppressedInjectionCount = "http.client.suppressed_injection_count"  // trace context injections suppressed by the propagation policy count total
+	metricHTTPClientPeerCertExpiry           = "http.client.peer_cert_days_until_expiry" // days until the upstream certificate expires
+	metricHTTPProxyUpstreamDuration          = "http.proxy.upstream_duration"            // time until the upstream response headers, milliseconds
+	metricHTTPProxyUpstreamErrorCount        = "http.proxy.upstream_error_count"         // failed proxied request count total
+	metricHTTPServerDuration                 = "http.server.duration"                    // Incoming end to end duration, milliseconds
+	metricHTTPServerRequestCount             = "http.server.request_count"               // Incoming request count total
+	metricHTTPServerTimeToFirstByte          = "http.server.time_to_first_byte"          // time to the first byte of streamed responses and hijacked connections, milliseconds
+	metricHTTPServerStreamMessageCount       = "http.server.stream_message_count"        // flushes of streamed responses and writes to hijacked connections count total
+	metricHTTPServerStreamBytes              = "http.server.stream_bytes"                // bytes written to streamed responses and hijacked connections total
+	metricHTTPServerPanics                   = "http.server.panics"                      // recovered panic count total
+	metricHTTPServerDeadlineExhaustedCount   = "http.server.deadline_exhausted_count"    // requests arriving with no deadline budget left count total
+	metricHTTPServerDependencyCalls          = "http.server.dependency_calls"            // database, cache and upstream HTTP calls per request making any, labelled by dependency kind
+	metricHTTPServerDependencyDuration       = "http.server.dependency_duration"         // time spent in database, cache and upstream HTTP calls per request making any, milliseconds
+	metricHTTPServerOpenConnections          = "http.server.open_connections"            // open connections
+	metricHTTPServerIdleConnections          = "http.server.idle_connections"            // idle keep-alive connections
+	metricHTTPServerActiveConnections        = "http.server.active_connections"          // connections serving a request
+	metricHTTPServerNewConnCount             = "http.server.new_connection_count"        // accepted connection count total
+	metricHTTPServerClosedConnCount          = "http.server.closed_connection_count"     // closed or hijacked connection count total
+	metricHTTPServerReusedConnCount          = "http.server.reused_connection_count"     // keep-alive connection reuse count total
 )
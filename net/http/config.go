@@ -1,6 +1,11 @@
 package http
 
 import (
+	"time"
+
+	otelzap "github.com/otel-contrib/instrumentation/go.uber.org/zap"
+	"github.com/otel-contrib/instrumentation/internal/bodycapture"
+	"github.com/otel-contrib/instrumentation/internal/header"
 	"go.opentelemetry.io/contrib"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/metric"
@@ -10,17 +15,67 @@ import (
 )
 
 type config struct {
-	tracerProvider    trace.TracerProvider
-	meterProvider     metric.MeterProvider
-	propagator        propagation.TextMapPropagator
-	operationName     string
-	spanNameFormatter SpanNameFormatter
+	tracerProvider          trace.TracerProvider
+	meterProvider           metric.MeterProvider
+	propagator              propagation.TextMapPropagator
+	serverName              string
+	operationName           string
+	spanNameFormatter       SpanNameFormatter
+	serverSpanNameFormatter SpanNameFormatter
+	clientTrace             bool
+	urlTemplates            urlTemplates
+	maxRetries              int
+	maxRetryWait            time.Duration
+	backoff                 Backoff
+	retryPolicy             RetryPolicy
+	requestHeaders          []string
+	responseHeaders         []string
+	sensitiveHeaders        header.Set
+	unmaskedHeaders         []string
+	bodyCapture             bodycapture.Config
+	redirectChain           bool
+	propagationRules        *PropagationPolicy
+	propagationPolicy       *propagationPolicy
+	upstreamPropagator      propagation.TextMapPropagator
+	proxyErrorHandler       func(ResponseWriter, *Request, error)
+	deadlineHeader          string
+	traceHeaders            traceHeaders
+	panicLogger             *otelzap.Logger
 
 	tracer                         trace.Tracer
 	meter                          metric.Meter
 	metricClientDuration           metric.Int64ValueRecorder
 	metricClientRequestCount       metric.Int64Counter
 	metricClientRequestFailedCount metric.Int64Counter
+	metricClientActiveRequests     metric.Int64UpDownCounter
+	metricClientConnCount          metric.Int64Counter
+	metricClientGetConnDuration    metric.Int64ValueRecorder
+	metricClientDNSDuration        metric.Int64ValueRecorder
+	metricClientConnectDuration    metric.Int64ValueRecorder
+	metricClientTLSDuration        metric.Int64ValueRecorder
+	metricClientFirstByteDuration  metric.Int64ValueRecorder
+	metricClientRequestSize        metric.Int64ValueRecorder
+	metricClientResponseSize       metric.Int64ValueRecorder
+	metricClientRetryCount         metric.Int64Counter
+	metricClientRetryGiveUpCount   metric.Int64Counter
+	metricClientSuppressedCount    metric.Int64Counter
+	metricProxyUpstreamDuration    metric.Int64ValueRecorder
+	metricProxyUpstreamErrorCount  metric.Int64Counter
+	metricServerDuration           metric.Int64ValueRecorder
+	metricServerRequestCount       metric.Int64Counter
+	metricServerDeadlineExhausted  metric.Int64Counter
+	metricServerDependencyCalls    metric.Int64ValueRecorder
+	metricServerDependencyDuration metric.Int64ValueRecorder
+	metricServerPanics             metric.Int64Counter
+	metricServerTimeToFirstByte    metric.Int64ValueRecorder
+	metricServerStreamMessageCount metric.Int64Counter
+	metricServerStreamBytes        metric.Int64Counter
+	metricServerOpenConnections    metric.Int64UpDownCounter
+	metricServerIdleConnections    metric.Int64UpDownCounter
+	metricServerActiveConnections  metric.Int64UpDownCounter
+	metricServerNewConnCount       metric.Int64Counter
+	metricServerClosedConnCount    metric.Int64Counter
+	metricServerReusedConnCount    metric.Int64Counter
 }
 
 // Option applies a configuration to the given config.
@@ -63,6 +118,14 @@ func WithPropagators(ps propagation.TextMapPropagator) Option {
 	})
 }
 
+// WithServerName specifies a server name.
+// If none is specified, the default server name is used.
+func WithServerName(name string) Option {
+	return OptionFunc(func(c *config) {
+		c.serverName = name
+	})
+}
+
 // WithOperationName specifies a operation name.
 // If none is specified, the default operation name is used
 func WithOperationName(name string) Option {
@@ -76,21 +139,217 @@ func WithOperationName(name string) Option {
 func WithSpanNameFormatter(f SpanNameFormatter) Option {
 	return OptionFunc(func(c *config) {
 		c.spanNameFormatter = f
+		c.serverSpanNameFormatter = f
+	})
+}
+
+// WithURLTemplates specifies path templates, such as /users/{id}, used to name outbound spans
+// and label client metrics. Paths matching no template have numeric and UUID segments collapsed.
+func WithURLTemplates(templates ...string) Option {
+	return OptionFunc(func(c *config) {
+		for _, t := range templates {
+			c.urlTemplates = append(c.urlTemplates, newURLTemplate(t))
+		}
+	})
+}
+
+// WithMaxRetries specifies the maximum number of retries made by the retry transport.
+// If none is specified, the default of 3 retries is used.
+func WithMaxRetries(n int) Option {
+	return OptionFunc(func(c *config) {
+		c.maxRetries = n
+	})
+}
+
+// WithMaxRetryWait specifies the longest wait the retry transport accepts from a Retry-After header.
+// A response asking for a longer wait is returned instead of being retried.
+// If none is specified, the default of 5s, the cap of DefaultBackoff, is used.
+func WithMaxRetryWait(d time.Duration) Option {
+	return OptionFunc(func(c *config) {
+		c.maxRetryWait = d
+	})
+}
+
+// WithBackoff specifies the delay between attempts made by the retry transport.
+// If none is specified, DefaultBackoff is used.
+func WithBackoff(b Backoff) Option {
+	return OptionFunc(func(c *config) {
+		c.backoff = b
+	})
+}
+
+// WithRetryPolicy specifies which responses and errors the retry transport retries.
+// If none is specified, DefaultRetryPolicy is used.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return OptionFunc(func(c *config) {
+		c.retryPolicy = p
+	})
+}
+
+// WithCapturedRequestHeaders specifies request headers recorded as http.request.header.<name> attributes.
+func WithCapturedRequestHeaders(headers ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.requestHeaders = append(c.requestHeaders, header.CanonicalKeys(headers)...)
+	})
+}
+
+// WithCapturedResponseHeaders specifies response headers recorded as http.response.header.<name> attributes.
+func WithCapturedResponseHeaders(headers ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.responseHeaders = append(c.responseHeaders, header.CanonicalKeys(headers)...)
+	})
+}
+
+// WithSensitiveHeaders specifies headers whose captured values are masked, in addition to
+// Authorization, Proxy-Authorization, Cookie and Set-Cookie, which are always masked
+// unless unmasked with WithUnmaskedHeaders.
+func WithSensitiveHeaders(headers ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.sensitiveHeaders.Add(headers...)
+	})
+}
+
+// WithUnmaskedHeaders specifies headers whose captured values are recorded as is,
+// even though they are masked by default or given to WithSensitiveHeaders.
+// Only use it for headers known to carry no credentials in your deployment.
+func WithUnmaskedHeaders(headers ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.unmaskedHeaders = append(c.unmaskedHeaders, headers...)
+	})
+}
+
+// WithBodyCapture enables recording up to maxBytes of the request and response bodies
+// as span events. Bodies are only recorded for transport errors and responses with
+// a status code of 400 or above, and still stream through to the caller unchanged.
+func WithBodyCapture(maxBytes int) Option {
+	return OptionFunc(func(c *config) {
+		c.bodyCapture.MaxBytes = maxBytes
+	})
+}
+
+// WithBodyCaptureContentTypes specifies the content types captured by WithBodyCapture.
+// A type ending in /* matches any subtype.
+// If none is specified, JSON, XML, form and text bodies are captured.
+func WithBodyCaptureContentTypes(contentTypes ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.bodyCapture.ContentTypes = contentTypes
+	})
+}
+
+// WithBodyRedactor specifies a redactor applied to captured bodies before they are recorded.
+func WithBodyRedactor(r BodyRedactor) Option {
+	return OptionFunc(func(c *config) {
+		c.bodyCapture.Redactor = r
+	})
+}
+
+// WithPropagationPolicy specifies which destinations outbound requests inject the trace context
+// and baggage into. If none is specified, every outbound request receives them.
+func WithPropagationPolicy(p PropagationPolicy) Option {
+	return OptionFunc(func(c *config) {
+		c.propagationRules = &p
+	})
+}
+
+// WithUpstreamPropagators specifies the propagators a reverse proxy injects the trace context
+// into the upstream requests with. Passing a composite of no propagators strips the trace headers.
+// If none is specified, the propagators given by WithPropagators are used.
+func WithUpstreamPropagators(ps propagation.TextMapPropagator) Option {
+	return OptionFunc(func(c *config) {
+		c.upstreamPropagator = ps
+	})
+}
+
+// WithProxyErrorHandler specifies the handler a reverse proxy calls once an error reaching
+// the upstream or returned by ModifyResponse has been recorded.
+// If none is specified, the error is logged and a 502 Bad Gateway is returned.
+func WithProxyErrorHandler(h func(ResponseWriter, *Request, error)) Option {
+	return OptionFunc(func(c *config) {
+		c.proxyErrorHandler = h
+	})
+}
+
+// WithDeadlineHeader enables deadline propagation through the given header.
+// Outbound requests carry the time left before the deadline of their context, in milliseconds,
+// unless the propagation policy suppresses the trace context for their destination,
+// and inbound requests carrying the header are served with a context bounded by it.
+func WithDeadlineHeader(name string) Option {
+	return OptionFunc(func(c *config) {
+		c.deadlineHeader = CanonicalHeaderKey(name)
+	})
+}
+
+// WithTraceResponse makes the server handler send the W3C traceresponse header,
+// carrying the trace ID and span ID of the server span.
+func WithTraceResponse() Option {
+	return OptionFunc(func(c *config) {
+		c.traceHeaders.TraceResponse = true
+	})
+}
+
+// WithServerTiming makes the server handler send a Server-Timing header with the total
+// handler time and the time spent in the database, cache and upstream HTTP calls made
+// through this module's instrumentation while handling the request.
+func WithServerTiming() Option {
+	return OptionFunc(func(c *config) {
+		c.traceHeaders.ServerTiming = true
+	})
+}
+
+// WithTraceHeadersFilter specifies which requests get the traceresponse and Server-Timing headers.
+// If none is specified, every request gets them once enabled.
+func WithTraceHeadersFilter(f TraceHeadersFilter) Option {
+	return OptionFunc(func(c *config) {
+		c.traceHeaders.filter = f
+	})
+}
+
+// WithPanicLogger specifies a logger the recovery handler logs panics to, along with the trace and span IDs.
+// If none is specified, panics are logged with the standard logger.
+func WithPanicLogger(logger *otelzap.Logger) Option {
+	return OptionFunc(func(c *config) {
+		c.panicLogger = logger
+	})
+}
+
+// WithClientTrace enables httptrace instrumentation of outbound requests.
+// Connection acquisition, DNS lookup, TCP connect, TLS handshake and the wait for the
+// first response byte are recorded as child spans of the client span.
+func WithClientTrace() Option {
+	return OptionFunc(func(c *config) {
+		c.clientTrace = true
+	})
+}
+
+// withRedirectChain makes the transport model the redirects followed by its client
+// as a logical request span with one child span per hop.
+func withRedirectChain() Option {
+	return OptionFunc(func(c *config) {
+		c.redirectChain = true
 	})
 }
 
 func newConfig(opts ...Option) (*config, error) {
 	var err error
 	c := &config{
-		tracerProvider:    otel.GetTracerProvider(),
-		meterProvider:     otel.GetMeterProvider(),
-		propagator:        otel.GetTextMapPropagator(),
-		operationName:     defaultOperationName,
-		spanNameFormatter: defaultSpanNameFormatter,
+		tracerProvider:          otel.GetTracerProvider(),
+		meterProvider:           otel.GetMeterProvider(),
+		propagator:              otel.GetTextMapPropagator(),
+		serverName:              defaultServerName,
+		operationName:           defaultOperationName,
+		spanNameFormatter:       defaultSpanNameFormatter,
+		serverSpanNameFormatter: defaultServerSpanNameFormatter,
+		maxRetries:              defaultMaxRetries,
+		maxRetryWait:            defaultMaxRetryWait,
+		backoff:                 DefaultBackoff,
+		retryPolicy:             DefaultRetryPolicy,
+		sensitiveHeaders:        header.NewSet(header.DefaultSensitive),
+		bodyCapture:             bodycapture.Config{ContentTypes: bodycapture.DefaultContentTypes},
 	}
 	for _, opt := range opts {
 		opt.Apply(c)
 	}
+	c.sensitiveHeaders.Remove(c.unmaskedHeaders...)
 
 	c.tracer = c.tracerProvider.Tracer(
 		defaultInstrumentationName,
@@ -125,10 +384,264 @@ func newConfig(opts ...Option) (*config, error) {
 	if err != nil {
 		return nil, err
 	}
+	c.metricClientActiveRequests, err = c.meter.NewInt64UpDownCounter(
+		metricHTTPClientActiveRequests,
+		metric.WithDescription("active requests"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricClientConnCount, err = c.meter.NewInt64Counter(
+		metricHTTPClientConnCount,
+		metric.WithDescription("connection count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricClientGetConnDuration, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPClientGetConnDuration,
+		metric.WithDescription("connection acquisition time in milliseconds"),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricClientDNSDuration, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPClientDNSDuration,
+		metric.WithDescription("dns lookup time in milliseconds"),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricClientConnectDuration, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPClientConnectDuration,
+		metric.WithDescription("connect time in milliseconds"),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricClientTLSDuration, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPClientTLSDuration,
+		metric.WithDescription("tls handshake time in milliseconds"),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricClientFirstByteDuration, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPClientFirstByteDuration,
+		metric.WithDescription("time to first response byte in milliseconds"),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricClientRequestSize, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPClientRequestSize,
+		metric.WithDescription("request body size in bytes"),
+		metric.WithUnit(unit.Bytes),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricClientResponseSize, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPClientResponseSize,
+		metric.WithDescription("response body size in bytes"),
+		metric.WithUnit(unit.Bytes),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricClientRetryCount, err = c.meter.NewInt64Counter(
+		metricHTTPClientRetryCount,
+		metric.WithDescription("retry count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricClientRetryGiveUpCount, err = c.meter.NewInt64Counter(
+		metricHTTPClientRetryGiveUpCount,
+		metric.WithDescription("retry give up count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricClientSuppressedCount, err = c.meter.NewInt64Counter(
+		metricHTTPClientSuppressedInjectionCount,
+		metric.WithDescription("suppressed injection count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricProxyUpstreamDuration, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPProxyUpstreamDuration,
+		metric.WithDescription("upstream response time in milliseconds"),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricProxyUpstreamErrorCount, err = c.meter.NewInt64Counter(
+		metricHTTPProxyUpstreamErrorCount,
+		metric.WithDescription("upstream error count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerDuration, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPServerDuration,
+		metric.WithDescription("request response time in milliseconds"),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerRequestCount, err = c.meter.NewInt64Counter(
+		metricHTTPServerRequestCount,
+		metric.WithDescription("request count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerTimeToFirstByte, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPServerTimeToFirstByte,
+		metric.WithDescription("time to first byte in milliseconds"),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerStreamMessageCount, err = c.meter.NewInt64Counter(
+		metricHTTPServerStreamMessageCount,
+		metric.WithDescription("stream message count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerStreamBytes, err = c.meter.NewInt64Counter(
+		metricHTTPServerStreamBytes,
+		metric.WithDescription("stream bytes"),
+		metric.WithUnit(unit.Bytes),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerPanics, err = c.meter.NewInt64Counter(
+		metricHTTPServerPanics,
+		metric.WithDescription("panic count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerDeadlineExhausted, err = c.meter.NewInt64Counter(
+		metricHTTPServerDeadlineExhaustedCount,
+		metric.WithDescription("deadline exhausted request count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerDependencyCalls, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPServerDependencyCalls,
+		metric.WithDescription("dependency calls per request"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerDependencyDuration, err = c.meter.NewInt64ValueRecorder(
+		metricHTTPServerDependencyDuration,
+		metric.WithDescription("dependency time per request in milliseconds"),
+		metric.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerOpenConnections, err = c.meter.NewInt64UpDownCounter(
+		metricHTTPServerOpenConnections,
+		metric.WithDescription("open connections"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerIdleConnections, err = c.meter.NewInt64UpDownCounter(
+		metricHTTPServerIdleConnections,
+		metric.WithDescription("idle connections"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerActiveConnections, err = c.meter.NewInt64UpDownCounter(
+		metricHTTPServerActiveConnections,
+		metric.WithDescription("active connections"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerNewConnCount, err = c.meter.NewInt64Counter(
+		metricHTTPServerNewConnCount,
+		metric.WithDescription("new connection count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerClosedConnCount, err = c.meter.NewInt64Counter(
+		metricHTTPServerClosedConnCount,
+		metric.WithDescription("closed connection count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.metricServerReusedConnCount, err = c.meter.NewInt64Counter(
+		metricHTTPServerReusedConnCount,
+		metric.WithDescription("reused connection count"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.propagationPolicy, err = newPropagationPolicy(c.propagationRules, c.metricClientSuppressedCount)
+	if err != nil {
+		return nil, err
+	}
 
 	return c, nil
 }
 
 func defaultSpanNameFormatter(operation string, req *Request) string {
-	return req.RequestURI
+	method := req.Method
+	if method == "" {
+		method = MethodGet
+	}
+	return "HTTP " + method + " " + URLTemplateFromContext(req.Context())
+}
+
+func defaultServerSpanNameFormatter(operation string, req *Request) string {
+	// The raw request URI is never used, as its path and query would make span names
+	// unbounded and may carry secrets.
+	route, ok := req.Context().Value(routeContextKey).(string)
+	if !ok {
+		return "HTTP " + req.Method
+	}
+	if route == "" {
+		return operation
+	}
+	return route
 }
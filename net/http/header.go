@@ -0,0 +1,11 @@
+package http
+
+import "net/http"
+
+// A Header represents the key-value pairs in an HTTP header.
+type Header = http.Header
+
+// CanonicalHeaderKey returns the canonical format of the header key s.
+func CanonicalHeaderKey(s string) string {
+	return http.CanonicalHeaderKey(s)
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"net/http"
+)
+
+// ServeMux is an HTTP request multiplexer that provides OpenTelemetry tracing and metrics.
+// The pattern that matched the request is recorded as http.route and used as the span name.
+type ServeMux struct {
+	mux     *http.ServeMux
+	handler Handler
+}
+
+type routeType struct{}
+
+var (
+	_ Handler = &ServeMux{}
+
+	routeContextKey = &routeType{}
+)
+
+// NewServeMux allocates and returns a new ServeMux.
+func NewServeMux(opts ...Option) (*ServeMux, error) {
+	mux := http.NewServeMux()
+	handler, err := NewHandler(mux, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ServeMux{
+		mux:     mux,
+		handler: handler,
+	}, nil
+}
+
+// RouteFromContext returns the ServeMux pattern that matched the request carrying ctx.
+// An empty string is returned if no pattern matched.
+func RouteFromContext(ctx context.Context) string {
+	route, _ := ctx.Value(routeContextKey).(string)
+	return route
+}
+
+// Handle registers the handler for the given pattern.
+// If a handler already exists for pattern, Handle panics.
+func (mux *ServeMux) Handle(pattern string, handler Handler) {
+	mux.mux.Handle(pattern, handler)
+}
+
+// HandleFunc registers the handler function for the given pattern.
+func (mux *ServeMux) HandleFunc(pattern string, handler func(ResponseWriter, *Request)) {
+	mux.mux.HandleFunc(pattern, handler)
+}
+
+// Handler returns the handler to use for the given request, consulting r.Method, r.Host, and r.URL.Path.
+// It always returns a non-nil handler.
+func (mux *ServeMux) Handler(r *Request) (h Handler, pattern string) {
+	return mux.mux.Handler(r)
+}
+
+// ServeHTTP dispatches the request to the handler whose pattern most closely matches the request URL.
+func (mux *ServeMux) ServeHTTP(w ResponseWriter, r *Request) {
+	_, pattern := mux.mux.Handler(r)
+	ctx := context.WithValue(r.Context(), routeContextKey, pattern)
+	mux.handler.ServeHTTP(w, r.WithContext(ctx))
+}
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"context"
+	"net/http/httptrace"
+	"time"
+
+	"go.opentelemetry.io/otel/label"
+)
+
+// connPoolTrace returns a ClientTrace recording how long a round trip waited for a
+// connection and whether the connection was reused from the idle pool.
+func (o *otelTransport) connPoolTrace(ctx context.Context, hostLabels []label.KeyValue) *httptrace.ClientTrace {
+	var start time.Time
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			start = time.Now()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			if !start.IsZero() {
+				o.metricGetConnDuration.Record(ctx, time.Since(start).Milliseconds(), hostLabels...)
+			}
+			labels := make([]label.KeyValue, 0, len(hostLabels)+1)
+			labels = append(labels, hostLabels...)
+			labels = append(labels, LabelKeyHTTPConnReused.Bool(info.Reused))
+			o.metricConnCount.Add(ctx, 1, labels...)
+		},
+	}
+}
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+const (
+	suppressedAll     = "all"
+	suppressedBaggage = "baggage"
+)
+
+// PropagationPolicy decides which outbound requests receive the trace context and baggage.
+//
+// Destinations are given as an exact host ("api.example.com"), a domain suffix
+// (".example.com" or "*.example.com", matching the domain and its subdomains)
+// or a CIDR block ("10.0.0.0/8", matching IP literal hosts).
+type PropagationPolicy struct {
+	// Allow lists the destinations that receive the trace context.
+	// If empty, every destination that is not denied receives it.
+	Allow []string
+	// Deny lists the destinations that never receive the trace context.
+	// Deny takes precedence over Allow.
+	Deny []string
+	// BaggageKeys restricts the baggage sent to a destination to the given keys.
+	// Destinations matching no entry receive all baggage; destinations matching
+	// several entries receive the union of their keys.
+	BaggageKeys map[string][]string
+}
+
+type propagationPolicy struct {
+	allow   []destination
+	deny    []destination
+	baggage []baggageRule
+
+	metricSuppressedCount metric.Int64Counter
+}
+
+type baggageRule struct {
+	destination destination
+	keys        map[label.Key]struct{}
+}
+
+type destination struct {
+	host   string
+	suffix string
+	cidr   *net.IPNet
+}
+
+func newPropagationPolicy(p *PropagationPolicy, counter metric.Int64Counter) (*propagationPolicy, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	var err error
+	policy := &propagationPolicy{metricSuppressedCount: counter}
+	if policy.allow, err = parseDestinations(p.Allow); err != nil {
+		return nil, err
+	}
+	if policy.deny, err = parseDestinations(p.Deny); err != nil {
+		return nil, err
+	}
+	for dest, keys := range p.BaggageKeys {
+		d, err := parseDestination(dest)
+		if err != nil {
+			return nil, err
+		}
+		rule := baggageRule{destination: d, keys: make(map[label.Key]struct{}, len(keys))}
+		for _, key := range keys {
+			rule.keys[label.Key(key)] = struct{}{}
+		}
+		policy.baggage = append(policy.baggage, rule)
+	}
+
+	return policy, nil
+}
+
+func parseDestinations(dests []string) ([]destination, error) {
+	ds := make([]destination, 0, len(dests))
+	for _, dest := range dests {
+		d, err := parseDestination(dest)
+		if err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+	return ds, nil
+}
+
+func parseDestination(dest string) (destination, error) {
+	dest = strings.ToLower(dest)
+	switch {
+	case strings.Contains(dest, "/"):
+		_, cidr, err := net.ParseCIDR(dest)
+		if err != nil {
+			return destination{}, err
+		}
+		return destination{cidr: cidr}, nil
+	case strings.HasPrefix(dest, "*."):
+		return destination{suffix: dest[1:]}, nil
+	case strings.HasPrefix(dest, "."):
+		return destination{suffix: dest}, nil
+	default:
+		return destination{host: dest}, nil
+	}
+}
+
+func (d destination) match(host string) bool {
+	switch {
+	case d.cidr != nil:
+		ip := net.ParseIP(host)
+		return ip != nil && d.cidr.Contains(ip)
+	case d.suffix != "":
+		return host == d.suffix[1:] || strings.HasSuffix(host, d.suffix)
+	default:
+		return host == d.host
+	}
+}
+
+func matchAny(ds []destination, host string) bool {
+	for _, d := range ds {
+		if d.match(host) {
+			return true
+		}
+	}
+	return false
+}
+
+// inject injects ctx into the headers of req unless the policy suppresses it
+// for the destination, dropping the baggage the destination may not receive,
+// and reports whether it did.
+// req.Header is replaced by a copy, so that the headers of the caller's request are left untouched,
+// and the propagator's fields already present, e.g. copied from an inbound request, are removed.
+func (p *propagationPolicy) inject(ctx context.Context, propagator propagation.TextMapPropagator, req *Request) bool {
+	if req.Header == nil {
+		req.Header = make(Header)
+	} else {
+		req.Header = req.Header.Clone()
+	}
+	for _, field := range propagator.Fields() {
+		req.Header.Del(field)
+	}
+
+	if p == nil {
+		propagator.Inject(ctx, req.Header)
+		return true
+	}
+
+	host := strings.ToLower(req.URL.Hostname())
+	labels := []label.KeyValue{semconv.NetPeerNameKey.String(host)}
+	if matchAny(p.deny, host) || (len(p.allow) > 0 && !matchAny(p.allow, host)) {
+		p.metricSuppressedCount.Add(ctx, 1, append(labels, LabelKeyHTTPPropagationSuppressed.String(suppressedAll))...)
+		return false
+	}
+
+	var (
+		matched bool
+		allowed = make(map[label.Key]struct{})
+	)
+	for _, rule := range p.baggage {
+		if !rule.destination.match(host) {
+			continue
+		}
+		matched = true
+		for key := range rule.keys {
+			allowed[key] = struct{}{}
+		}
+	}
+	if matched {
+		var drop []label.Key
+		set := baggage.Set(ctx)
+		for iter := set.Iter(); iter.Next(); {
+			key := iter.Label().Key
+			if _, ok := allowed[key]; !ok {
+				drop = append(drop, key)
+			}
+		}
+		if len(drop) > 0 {
+			ctx = baggage.ContextWithoutValues(ctx, drop...)
+			p.metricSuppressedCount.Add(ctx, 1, append(labels, LabelKeyHTTPPropagationSuppressed.String(suppressedBaggage))...)
+		}
+	}
+
+	propagator.Inject(ctx, req.Header)
+	return true
+}
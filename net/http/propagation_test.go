@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestDestinationMatch(t *testing.T) {
+	tests := []struct {
+		dest string
+		host string
+		want bool
+	}{
+		{dest: "api.example.com", host: "api.example.com", want: true},
+		{dest: "API.Example.com", host: "api.example.com", want: true},
+		{dest: "api.example.com", host: "www.api.example.com", want: false},
+		{dest: "api.example.com", host: "example.com", want: false},
+		{dest: ".example.com", host: "example.com", want: true},
+		{dest: ".example.com", host: "api.example.com", want: true},
+		{dest: ".example.com", host: "a.b.example.com", want: true},
+		{dest: ".example.com", host: "badexample.com", want: false},
+		{dest: "*.example.com", host: "example.com", want: true},
+		{dest: "*.example.com", host: "api.example.com", want: true},
+		{dest: "*.example.com", host: "example.org", want: false},
+		{dest: "10.0.0.0/8", host: "10.1.2.3", want: true},
+		{dest: "10.0.0.0/8", host: "11.1.2.3", want: false},
+		{dest: "10.0.0.0/8", host: "internal.example.com", want: false},
+		{dest: "fd00::/8", host: "fd00::1", want: true},
+		{dest: "fd00::/8", host: "fe80::1", want: false},
+	}
+	for _, tt := range tests {
+		d, err := parseDestination(tt.dest)
+		if err != nil {
+			t.Fatalf("parseDestination(%q): %v", tt.dest, err)
+		}
+		if got := d.match(tt.host); got != tt.want {
+			t.Errorf("destination %q matching %q = %v, want %v", tt.dest, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestParseDestinationInvalidCIDR(t *testing.T) {
+	if _, err := parseDestination("10.0.0.0/33"); err == nil {
+		t.Error("parseDestination accepted an invalid CIDR block")
+	}
+}
+
+func newTestPropagationPolicy(t *testing.T, p PropagationPolicy) *propagationPolicy {
+	c, err := newConfig(WithPropagationPolicy(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.propagationPolicy
+}
+
+func testSpanContext() (context.Context, trace.Span) {
+	return oteltest.NewTracerProvider().Tracer("test").Start(context.Background(), "client")
+}
+
+func TestPropagationPolicyInject(t *testing.T) {
+	policy := newTestPropagationPolicy(t, PropagationPolicy{
+		Allow: []string{".internal.example.com", "10.0.0.0/8"},
+		Deny:  []string{"billing.internal.example.com"},
+	})
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{url: "http://api.internal.example.com/", want: true},
+		{url: "http://10.1.2.3:8080/", want: true},
+		{url: "http://billing.internal.example.com/", want: false},
+		{url: "https://api.partner.com/", want: false},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+		req.Header.Set("Traceparent", "00-22222222222222222222222222222222-2222222222222222-01")
+		callerHeader := req.Header
+
+		ctx, span := testSpanContext()
+		propagated := policy.inject(ctx, propagation.TraceContext{}, req)
+		if propagated != tt.want {
+			t.Errorf("%s: inject() = %v, want %v", tt.url, propagated, tt.want)
+		}
+		if got := req.Header.Get("Traceparent") != ""; got != tt.want {
+			t.Errorf("%s: traceparent sent = %v, want %v", tt.url, got, tt.want)
+		}
+		if got, traceID := req.Header.Get("Traceparent"), span.SpanContext().TraceID.String(); got != "" && got[3:35] != traceID {
+			t.Errorf("%s: traceparent = %q, want the injected trace", tt.url, got)
+		}
+		if got := callerHeader.Get("Traceparent"); got != "00-22222222222222222222222222222222-2222222222222222-01" {
+			t.Errorf("%s: caller's header changed to %q", tt.url, got)
+		}
+	}
+}
+
+func TestPropagationPolicyBaggage(t *testing.T) {
+	policy := newTestPropagationPolicy(t, PropagationPolicy{
+		BaggageKeys: map[string][]string{
+			".partner.com":    {"tenant"},
+			"api.partner.com": {"region"},
+		},
+	})
+	ctx := baggage.ContextWithValues(context.Background(),
+		label.String("tenant", "acme"),
+		label.String("region", "eu"),
+		label.String("user", "alice"),
+	)
+
+	tests := []struct {
+		url  string
+		want map[string]bool
+	}{
+		{url: "http://api.partner.com/", want: map[string]bool{"tenant": true, "region": true}},
+		{url: "http://www.partner.com/", want: map[string]bool{"tenant": true}},
+		{url: "http://other.com/", want: map[string]bool{"tenant": true, "region": true, "user": true}},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+		policy.inject(ctx, propagation.Baggage{}, req)
+
+		got := baggage.Set(propagation.Baggage{}.Extract(context.Background(), req.Header))
+		for _, key := range []string{"tenant", "region", "user"} {
+			_, ok := got.Value(label.Key(key))
+			if ok != tt.want[key] {
+				t.Errorf("%s: baggage %q sent = %v, want %v", tt.url, key, ok, tt.want[key])
+			}
+		}
+	}
+}
+
+func TestNilPropagationPolicyStripsStaleHeaders(t *testing.T) {
+	var policy *propagationPolicy
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+	req.Header.Set("Traceparent", "00-22222222222222222222222222222222-2222222222222222-01")
+
+	if !policy.inject(context.Background(), propagation.TraceContext{}, req) {
+		t.Error("inject() = false without a policy")
+	}
+	if got := req.Header.Get("Traceparent"); got != "" {
+		t.Errorf("stale traceparent %q sent with no span in the context", got)
+	}
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ReverseProxy wraps an httputil.ReverseProxy with a server span for every inbound request
+// and a client child span for the request made to the upstream.
+// Fields not overridden here, such as Director and ModifyResponse, are promoted from the
+// embedded httputil.ReverseProxy. Replacing its Transport drops the upstream spans and metrics,
+// and replacing its ErrorHandler drops the error recording: use WithProxyErrorHandler instead.
+type ReverseProxy struct {
+	*httputil.ReverseProxy
+
+	handler      Handler
+	propagator   propagation.TextMapPropagator
+	errorHandler func(ResponseWriter, *Request, error)
+
+	metricUpstreamDuration   metric.Int64ValueRecorder
+	metricUpstreamErrorCount metric.Int64Counter
+}
+
+var _ Handler = &ReverseProxy{}
+
+type upstreamTransport struct {
+	rt               RoundTripper
+	metricDuration   metric.Int64ValueRecorder
+	metricErrorCount metric.Int64Counter
+}
+
+// NewReverseProxy returns a ReverseProxy that routes requests to target, as
+// httputil.NewSingleHostReverseProxy does.
+// The trace headers received from the downstream are removed before the request is proxied,
+// and the upstream receives the context of the client span instead, injected with the
+// propagators given by WithUpstreamPropagators.
+func NewReverseProxy(target *url.URL, opts ...Option) (*ReverseProxy, error) {
+	c, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	transportOpts := opts
+	if c.upstreamPropagator != nil {
+		transportOpts = append(transportOpts[:len(transportOpts):len(transportOpts)], WithPropagators(c.upstreamPropagator))
+	}
+	rt, err := NewOTelTransport(DefaultTransport, transportOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ReverseProxy{
+		ReverseProxy:             httputil.NewSingleHostReverseProxy(target),
+		propagator:               c.propagator,
+		errorHandler:             c.proxyErrorHandler,
+		metricUpstreamDuration:   c.metricProxyUpstreamDuration,
+		metricUpstreamErrorCount: c.metricProxyUpstreamErrorCount,
+	}
+	p.ReverseProxy.Transport = &upstreamTransport{
+		rt:               rt,
+		metricDuration:   c.metricProxyUpstreamDuration,
+		metricErrorCount: c.metricProxyUpstreamErrorCount,
+	}
+	p.ReverseProxy.ErrorHandler = p.handleError
+
+	p.handler, err = NewHandler(HandlerFunc(p.serveUpstream), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// ServeHTTP proxies req to the upstream under a server span.
+func (p *ReverseProxy) ServeHTTP(w ResponseWriter, req *Request) {
+	p.handler.ServeHTTP(w, req)
+}
+
+func (p *ReverseProxy) serveUpstream(w ResponseWriter, req *Request) {
+	for _, field := range p.propagator.Fields() {
+		req.Header.Del(field)
+	}
+	p.ReverseProxy.ServeHTTP(w, req)
+}
+
+// handleError records err on the server span before handing it to the error handler
+// given by WithProxyErrorHandler.
+// req is the outbound request, whose URL points at the upstream.
+func (p *ReverseProxy) handleError(w ResponseWriter, req *Request, err error) {
+	ctx := req.Context()
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	p.metricUpstreamErrorCount.Add(ctx, 1, LabelKeyHTTPProxyUpstream.String(req.URL.Host))
+
+	if p.errorHandler != nil {
+		p.errorHandler(w, req, err)
+		return
+	}
+	if p.ErrorLog != nil {
+		p.ErrorLog.Printf("http: proxy error: %v", err)
+	} else {
+		log.Printf("http: proxy error: %v", err)
+	}
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+func (t *upstreamTransport) RoundTrip(req *Request) (*Response, error) {
+	start := time.Now()
+	resp, err := t.rt.RoundTrip(req)
+	elapsedTime := time.Since(start).Milliseconds()
+	upstream := LabelKeyHTTPProxyUpstream.String(req.URL.Host)
+	t.metricDuration.Record(req.Context(), elapsedTime, upstream)
+	// Errors reaching the upstream are counted by handleError, server errors it answers with here.
+	if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+		t.metricErrorCount.Add(req.Context(), 1, upstream, semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	}
+	return resp, err
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	otelzap "github.com/otel-contrib/instrumentation/go.uber.org/zap"
+	"github.com/otel-contrib/instrumentation/internal/exception"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type recoveryHandler struct {
+	handler Handler
+
+	panicLogger *otelzap.Logger
+
+	metricPanics metric.Int64Counter
+}
+
+var _ Handler = &recoveryHandler{}
+
+// NewRecoveryHandler wraps the provided Handler with one that recovers from panics and writes a 500
+// if the response has not been started. The panic is recorded as an exception event on the span of
+// the request, which is marked as Error, so the handler is meant to be wrapped by NewHandler.
+// http.ErrAbortHandler is not recovered.
+func NewRecoveryHandler(h Handler, opts ...Option) (Handler, error) {
+	c, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recoveryHandler{
+		handler:      h,
+		panicLogger:  c.panicLogger,
+		metricPanics: c.metricServerPanics,
+	}, nil
+}
+
+func (o *recoveryHandler) ServeHTTP(w ResponseWriter, req *Request) {
+	rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		if r == http.ErrAbortHandler {
+			panic(r)
+		}
+
+		ctx := req.Context()
+		stack := debug.Stack()
+		exception.Record(ctx, trace.SpanFromContext(ctx), r, stack)
+		o.metricPanics.Add(ctx, 1, semconv.HTTPRouteKey.String(RouteFromContext(ctx)))
+		o.logPanic(ctx, req, r, stack)
+
+		if !rw.wroteHeader {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+	}()
+
+	o.handler.ServeHTTP(rw.exposed(), req)
+}
+
+func (o *recoveryHandler) logPanic(ctx context.Context, req *Request, r interface{}, stack []byte) {
+	if o.panicLogger == nil {
+		log.Printf("http: panic serving %v: %v\n%s", req.RemoteAddr, r, stack)
+		return
+	}
+	o.panicLogger.ErrorWithContext(ctx, "panic recovered",
+		zap.String("panic", fmt.Sprint(r)),
+		zap.ByteString("stacktrace", stack),
+	)
+}
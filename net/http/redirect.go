@@ -0,0 +1,157 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultMaxRedirects = 10
+
+type redirectChainType struct{}
+
+var redirectChainContextKey = &redirectChainType{}
+
+// redirectChain is the logical request spanning every hop of a redirect chain
+// followed by a client created with NewClient. It starts with the first hop, and ends
+// with it if the response is not a redirect.
+type redirectChain struct {
+	span trace.Span
+
+	mu       sync.Mutex
+	hops     int
+	followed bool
+	once     sync.Once
+}
+
+// redirectChainOf returns the redirect chain req is the next hop of, if any.
+func redirectChainOf(req *Request) *redirectChain {
+	if req.Response == nil || req.Response.Request == nil {
+		return nil
+	}
+	chain, _ := req.Response.Request.Context().Value(redirectChainContextKey).(*redirectChain)
+	return chain
+}
+
+// startRedirectHop returns the context the span of the next hop of the chain is started from,
+// carrying the chain so that the client hands it to the hop after, and the number of the hop.
+func (rc *redirectChain) startRedirectHop(ctx context.Context) (context.Context, int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	hop := rc.hops
+	rc.hops++
+	rc.followed = false
+
+	ctx = context.WithValue(ctx, redirectChainContextKey, rc)
+	return trace.ContextWithSpan(ctx, rc.span), hop
+}
+
+// startRedirectChain starts the chain of a first hop, its span a child of ctx.
+func (o *otelTransport) startRedirectChain(ctx context.Context, req *Request) *redirectChain {
+	_, span := o.tracer.Start(ctx, o.spanNameFormatter(o.operationName, req),
+		trace.WithSpanKind(trace.SpanKindInternal),
+	)
+	return &redirectChain{span: span}
+}
+
+// hopDone is called once a hop span has ended. The chain ends with its final hop,
+// which is not a redirect.
+func (rc *redirectChain) hopDone(final bool) {
+	if rc != nil && final {
+		rc.end()
+	}
+}
+
+// follow is called when CheckRedirect allows the client to follow the last redirect.
+func (rc *redirectChain) follow() {
+	rc.mu.Lock()
+	rc.followed = true
+	rc.mu.Unlock()
+}
+
+// redirectBody wraps the body of a redirect response. The client closes it both when it
+// follows the redirect and when it gives up on it, e.g. if CheckRedirect refuses it or the
+// request body cannot be rewound, and the chain ends then unless the redirect was followed.
+// Returned by CheckRedirect's ErrUseLastResponse, the body is closed by the caller instead.
+func (rc *redirectChain) redirectBody(body io.ReadCloser) io.ReadCloser {
+	if body == nil {
+		body = http.NoBody
+	}
+	return &redirectBody{ReadCloser: body, chain: rc}
+}
+
+type redirectBody struct {
+	io.ReadCloser
+	chain *redirectChain
+	once  sync.Once
+}
+
+func (b *redirectBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.chain.mu.Lock()
+		followed := b.chain.followed
+		b.chain.mu.Unlock()
+		if !followed {
+			b.chain.end()
+		}
+	})
+	return err
+}
+
+func (rc *redirectChain) end() {
+	rc.once.Do(func() {
+		rc.mu.Lock()
+		redirects := rc.hops - 1
+		rc.mu.Unlock()
+		rc.span.SetAttributes(LabelKeyHTTPRedirectCount.Int(redirects))
+		rc.span.End()
+	})
+}
+
+// isRedirect reports whether the client is going to consider following resp,
+// mirroring the redirect behavior of http.Client.
+func isRedirect(req *Request, resp *Response) bool {
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther:
+	case http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		if req.GetBody == nil && req.Body != nil && req.Body != http.NoBody {
+			return false
+		}
+	default:
+		return false
+	}
+
+	loc := resp.Header.Get("Location")
+	if loc == "" {
+		return false
+	}
+	_, err := req.URL.Parse(loc)
+	return err == nil
+}
+
+// checkRedirect wraps the CheckRedirect policy of a client so that the redirect chain
+// goes on when the policy allows following a redirect.
+func checkRedirect(next func(req *Request, via []*Request) error) func(req *Request, via []*Request) error {
+	if next == nil {
+		next = defaultCheckRedirect
+	}
+	return func(req *Request, via []*Request) error {
+		err := next(req, via)
+		if chain := redirectChainOf(req); chain != nil && err == nil {
+			chain.follow()
+		}
+		return err
+	}
+}
+
+func defaultCheckRedirect(req *Request, via []*Request) error {
+	if len(via) >= defaultMaxRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newRedirectServer serves /hops/<n>, which redirects n times with the given status before responding.
+func newRedirectServer(t *testing.T, status int) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/hops/2":
+			http.Redirect(w, r, "/hops/1", status)
+		case "/hops/1":
+			http.Redirect(w, r, "/hops/0", status)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(t *testing.T, checkRedirect func(req *Request, via []*Request) error) (*Client, *oteltest.StandardSpanRecorder) {
+	sr := new(oteltest.StandardSpanRecorder)
+	tp := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+	c, err := NewClient(&Client{CheckRedirect: checkRedirect}, WithTracerProvider(tp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, sr
+}
+
+// checkRedirectSpans checks that every span started has ended, and that the hops are the
+// children of a single logical request span recording the given number of redirects.
+func checkRedirectSpans(t *testing.T, sr *oteltest.StandardSpanRecorder, wantHops, wantRedirects int) {
+	t.Helper()
+
+	var chain *oteltest.Span
+	var hops []*oteltest.Span
+	for _, span := range sr.Started() {
+		if !span.Ended() {
+			t.Errorf("span %q (%s) was never ended", span.Name(), span.SpanKind())
+		}
+		switch span.SpanKind() {
+		case trace.SpanKindInternal:
+			if chain != nil {
+				t.Fatal("more than one logical request span")
+			}
+			chain = span
+		case trace.SpanKindClient:
+			hops = append(hops, span)
+		}
+	}
+	if chain == nil {
+		t.Fatal("no logical request span")
+	}
+	if got := chain.Attributes()[LabelKeyHTTPRedirectCount].AsInt64(); got != int64(wantRedirects) {
+		t.Errorf("%s = %d, want %d", LabelKeyHTTPRedirectCount, got, wantRedirects)
+	}
+	if len(hops) != wantHops {
+		t.Fatalf("%d hop spans, want %d", len(hops), wantHops)
+	}
+	for i, hop := range hops {
+		if hop.ParentSpanID() != chain.SpanContext().SpanID {
+			t.Errorf("hop %d is not a child of the logical request span", i)
+		}
+		if got := hop.Attributes()[LabelKeyHTTPRedirectHop].AsInt64(); got != int64(i) {
+			t.Errorf("hop %d: %s = %d", i, LabelKeyHTTPRedirectHop, got)
+		}
+	}
+}
+
+func TestClientRedirectChain(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		wantHops      int
+		wantRedirects int
+	}{
+		{name: "no redirect", path: "/hops/0", wantHops: 1, wantRedirects: 0},
+		{name: "one redirect", path: "/hops/1", wantHops: 2, wantRedirects: 1},
+		{name: "two redirects", path: "/hops/2", wantHops: 3, wantRedirects: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newRedirectServer(t, http.StatusFound)
+			c, sr := newTestClient(t, nil)
+
+			resp, err := c.Get(srv.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if string(body) != "ok" {
+				t.Fatalf("body = %q, want %q", body, "ok")
+			}
+
+			checkRedirectSpans(t, sr, tt.wantHops, tt.wantRedirects)
+		})
+	}
+}
+
+func TestClientRedirectRefused(t *testing.T) {
+	srv := newRedirectServer(t, http.StatusFound)
+	refused := errors.New("refused")
+	c, sr := newTestClient(t, func(req *Request, via []*Request) error {
+		if len(via) >= 2 {
+			return refused
+		}
+		return nil
+	})
+
+	_, err := c.Get(srv.URL + "/hops/2")
+	if !errors.Is(err, refused) {
+		t.Fatalf("Get() error = %v, want %v", err, refused)
+	}
+
+	checkRedirectSpans(t, sr, 2, 1)
+}
+
+func TestClientRedirectUseLastResponse(t *testing.T) {
+	srv := newRedirectServer(t, http.StatusFound)
+	c, sr := newTestClient(t, func(req *Request, via []*Request) error {
+		return http.ErrUseLastResponse
+	})
+
+	resp, err := c.Get(srv.URL + "/hops/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+	for _, span := range sr.Started() {
+		if span.SpanKind() == trace.SpanKindInternal && span.Ended() {
+			t.Fatal("logical request span ended before the caller closed the last response")
+		}
+	}
+	resp.Body.Close()
+
+	checkRedirectSpans(t, sr, 1, 0)
+}
+
+func TestClientRedirectGetBodyFails(t *testing.T) {
+	srv := newRedirectServer(t, http.StatusTemporaryRedirect)
+	c, sr := newTestClient(t, nil)
+
+	req, err := NewRequest(MethodPost, srv.URL+"/hops/1", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return nil, errors.New("body gone")
+	}
+
+	if _, err := c.Do(req); err == nil {
+		t.Fatal("Do() succeeded although the request body could not be rewound")
+	}
+
+	checkRedirectSpans(t, sr, 1, 0)
+}
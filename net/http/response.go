@@ -3,7 +3,61 @@ Package http provides HTTP client and server implementations.
 */
 package http
 
-import "net/http"
+import (
+	"io"
+	"net/http"
+	"sync"
+)
 
 // Response represents the response from an HTTP request.
 type Response = http.Response
+
+// responseBody wraps a response body and reports the number of bytes read
+// once the body has been read to EOF, has failed, or has been closed.
+type responseBody struct {
+	rc     io.ReadCloser
+	n      int64
+	once   sync.Once
+	onDone func(n int64, err error)
+}
+
+// rwResponseBody preserves io.Writer for the bodies of 101 Switching Protocols responses.
+type rwResponseBody struct {
+	*responseBody
+}
+
+func newResponseBody(rc io.ReadCloser, onDone func(n int64, err error)) io.ReadCloser {
+	b := &responseBody{rc: rc, onDone: onDone}
+	if _, ok := rc.(io.ReadWriteCloser); ok {
+		return &rwResponseBody{responseBody: b}
+	}
+	return b
+}
+
+func (b *responseBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	b.n += int64(n)
+	switch {
+	case err == io.EOF:
+		b.done(nil)
+	case err != nil:
+		b.done(err)
+	}
+	return n, err
+}
+
+func (b *responseBody) Close() error {
+	err := b.rc.Close()
+	b.done(nil)
+	return err
+}
+
+func (b *responseBody) done(err error) {
+	b.once.Do(func() {
+		b.onDone(b.n, err)
+	})
+}
+
+func (b *rwResponseBody) Write(p []byte) (int, error) {
+	return b.rc.(io.Writer).Write(p)
+}
@@ -0,0 +1,232 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Backoff returns how long to wait before the given retry, starting at 1.
+type Backoff func(retry int) time.Duration
+
+// RetryPolicy reports whether req should be retried after an attempt
+// returned the given response or error.
+type RetryPolicy func(req *Request, resp *Response, err error) bool
+
+type retryTransport struct {
+	rt RoundTripper
+
+	tracerProvider    trace.TracerProvider
+	meterProvider     metric.MeterProvider
+	propagator        propagation.TextMapPropagator
+	operationName     string
+	maxRetries        int
+	maxRetryWait      time.Duration
+	backoff           Backoff
+	retryPolicy       RetryPolicy
+	propagationPolicy *propagationPolicy
+	deadlineHeader    string
+
+	tracer                 trace.Tracer
+	meter                  metric.Meter
+	metricRetryCount       metric.Int64Counter
+	metricRetryGiveUpCount metric.Int64Counter
+}
+
+var _ RoundTripper = &retryTransport{}
+
+// NewRetryTransport wraps the provided RoundTripper with one that retries failed attempts.
+// Every attempt gets its own child span carrying http.retry_count, so the transport is
+// meant to be wrapped by NewOTelTransport, whose span then covers the whole logical request.
+// Requests with a body are only retried if GetBody is set.
+// If none is specified, the DefaultTransport is used.
+func NewRetryTransport(rt RoundTripper, opts ...Option) (RoundTripper, error) {
+	c, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if rt == nil {
+		rt = DefaultTransport
+	}
+
+	return &retryTransport{
+		rt:                     rt,
+		tracerProvider:         c.tracerProvider,
+		meterProvider:          c.meterProvider,
+		propagator:             c.propagator,
+		operationName:          c.operationName,
+		maxRetries:             c.maxRetries,
+		maxRetryWait:           c.maxRetryWait,
+		backoff:                c.backoff,
+		retryPolicy:            c.retryPolicy,
+		propagationPolicy:      c.propagationPolicy,
+		deadlineHeader:         c.deadlineHeader,
+		tracer:                 c.tracer,
+		meter:                  c.meter,
+		metricRetryCount:       c.metricClientRetryCount,
+		metricRetryGiveUpCount: c.metricClientRetryGiveUpCount,
+	}, nil
+}
+
+// DefaultBackoff is an exponential backoff starting at 100ms and capped at 5s, with full jitter.
+func DefaultBackoff(retry int) time.Duration {
+	return ExponentialBackoff(100*time.Millisecond, defaultMaxRetryWait)(retry)
+}
+
+// ExponentialBackoff returns a Backoff that doubles the delay on every retry,
+// up to maxDelay, and picks a random delay below it.
+func ExponentialBackoff(base, maxDelay time.Duration) Backoff {
+	return func(retry int) time.Duration {
+		d := maxDelay
+		if retry < 32 {
+			if exp := base << uint(retry-1); exp > 0 && exp < maxDelay {
+				d = exp
+			}
+		}
+		return time.Duration(rand.Int63n(int64(d) + 1))
+	}
+}
+
+// DefaultRetryPolicy retries idempotent requests after transport errors other than context cancellation,
+// and after 429, 502, 503 and 504 responses. As with http.Transport, requests are idempotent if their method
+// is GET, HEAD, OPTIONS, TRACE, PUT or DELETE, or if they carry an Idempotency-Key or X-Idempotency-Key header,
+// so that a POST the server may already have processed is not sent again.
+func DefaultRetryPolicy(req *Request, resp *Response, err error) bool {
+	if !isIdempotent(req) {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func isIdempotent(req *Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	if _, ok := req.Header["Idempotency-Key"]; ok {
+		return true
+	}
+	_, ok := req.Header["X-Idempotency-Key"]
+	return ok
+}
+
+func (o *retryTransport) RoundTrip(req *Request) (*Response, error) {
+	ctx := req.Context()
+	labels := []label.KeyValue{semconv.NetPeerNameKey.String(req.URL.Hostname())}
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for retry := 0; ; retry++ {
+		attempt := req
+		if retry > 0 {
+			attempt = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attempt.Body = body
+			}
+		}
+
+		resp, err := o.roundTrip(attempt, retry)
+		if !o.retryPolicy(req, resp, err) {
+			return resp, err
+		}
+		if retry >= o.maxRetries || !rewindable {
+			o.metricRetryGiveUpCount.Add(ctx, 1, labels...)
+			return resp, err
+		}
+
+		wait := o.backoff(retry + 1)
+		if resp != nil {
+			if d, ok := retryAfter(resp); ok {
+				if d > o.maxRetryWait {
+					// Rather than sleeping past the limit, the caller gets the response asking to wait.
+					o.metricRetryGiveUpCount.Add(ctx, 1, labels...)
+					return resp, err
+				}
+				wait = d
+			}
+			drainBody(resp.Body)
+		}
+
+		o.metricRetryCount.Add(ctx, 1, labels...)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (o *retryTransport) roundTrip(req *Request, retry int) (*Response, error) {
+	ctx, span := o.tracer.Start(req.Context(), o.operationName+".attempt",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(LabelKeyHTTPRetryCount.Int(retry)),
+	)
+	defer span.End()
+
+	req = req.WithContext(ctx)
+	propagated := o.propagationPolicy.inject(ctx, o.propagator, req)
+	// Each attempt advertises the budget left after the previous ones and their backoff.
+	injectDeadline(ctx, o.deadlineHeader, propagated, req, span)
+
+	resp, err := o.rt.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)...)
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(resp.StatusCode))
+
+	return resp, err
+}
+
+// retryAfter parses the Retry-After header, given either in seconds or as an HTTP date.
+func retryAfter(resp *Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// drainBody reads a little of the body before closing it so that the connection can be reused.
+func drainBody(body io.ReadCloser) {
+	if body == nil {
+		return
+	}
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(body, 4096))
+	body.Close()
+}
@@ -1,6 +1,28 @@
 package http
 
-import "net/http"
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/otel-contrib/instrumentation/internal/deadline"
+	"github.com/otel-contrib/instrumentation/internal/dependency"
+	"github.com/otel-contrib/instrumentation/internal/exception"
+	"github.com/otel-contrib/instrumentation/internal/header"
+	"github.com/otel-contrib/instrumentation/internal/stream"
+	"github.com/otel-contrib/instrumentation/internal/tlsinfo"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
 
 // A Handler responds to an HTTP request.
 type Handler = http.Handler
@@ -8,3 +30,415 @@ type Handler = http.Handler
 // The HandlerFunc type is an adapter to allow the use of ordinary functions as HTTP handlers.
 // If f is a function with the appropriate signature, HandlerFunc(f) is a Handler that calls f.
 type HandlerFunc = http.HandlerFunc
+
+// A ResponseWriter interface is used by an HTTP handler to construct an HTTP response.
+type ResponseWriter = http.ResponseWriter
+
+type otelHandler struct {
+	handler Handler
+
+	tracerProvider    trace.TracerProvider
+	meterProvider     metric.MeterProvider
+	propagator        propagation.TextMapPropagator
+	serverName        string
+	operationName     string
+	spanNameFormatter SpanNameFormatter
+	requestHeaders    []string
+	responseHeaders   []string
+	sensitiveHeaders  header.Set
+	deadlineHeader    string
+	traceHeaders      traceHeaders
+
+	tracer                  trace.Tracer
+	meter                   metric.Meter
+	metricDuration          metric.Int64ValueRecorder
+	metricRequestCount      metric.Int64Counter
+	metricDeadlineExhausted metric.Int64Counter
+
+	metricDependencyCalls    metric.Int64ValueRecorder
+	metricDependencyDuration metric.Int64ValueRecorder
+
+	metricTimeToFirstByte    metric.Int64ValueRecorder
+	metricStreamMessageCount metric.Int64Counter
+	metricStreamBytes        metric.Int64Counter
+}
+
+var _ Handler = &otelHandler{}
+
+// NewHandler wraps the provided Handler with one that extracts the span context from the
+// inbound request headers, starts a server span and records metrics.
+func NewHandler(h Handler, opts ...Option) (Handler, error) {
+	c, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &otelHandler{
+		handler:                  h,
+		tracerProvider:           c.tracerProvider,
+		meterProvider:            c.meterProvider,
+		propagator:               c.propagator,
+		serverName:               c.serverName,
+		operationName:            c.operationName,
+		spanNameFormatter:        c.serverSpanNameFormatter,
+		requestHeaders:           c.requestHeaders,
+		responseHeaders:          c.responseHeaders,
+		sensitiveHeaders:         c.sensitiveHeaders,
+		deadlineHeader:           c.deadlineHeader,
+		traceHeaders:             c.traceHeaders,
+		tracer:                   c.tracer,
+		meter:                    c.meter,
+		metricDuration:           c.metricServerDuration,
+		metricRequestCount:       c.metricServerRequestCount,
+		metricDeadlineExhausted:  c.metricServerDeadlineExhausted,
+		metricDependencyCalls:    c.metricServerDependencyCalls,
+		metricDependencyDuration: c.metricServerDependencyDuration,
+		metricTimeToFirstByte:    c.metricServerTimeToFirstByte,
+		metricStreamMessageCount: c.metricServerStreamMessageCount,
+		metricStreamBytes:        c.metricServerStreamBytes,
+	}
+
+	return o, nil
+}
+
+func (o *otelHandler) ServeHTTP(w ResponseWriter, req *Request) {
+	start := time.Now()
+
+	route := RouteFromContext(req.Context())
+	ctx := o.propagator.Extract(req.Context(), req.Header)
+	ctx, span := o.tracer.Start(ctx, o.spanNameFormatter(o.operationName, req),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.NetAttributesFromHTTPRequest("tcp", req)...),
+		trace.WithAttributes(semconv.EndUserAttributesFromHTTPRequest(req)...),
+		trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(o.serverName, route, req)...),
+		trace.WithAttributes(header.Attributes(header.RequestPrefix, req.Header, o.requestHeaders, o.sensitiveHeaders)...),
+	)
+	if req.TLS != nil {
+		span.SetAttributes(tlsinfo.Attributes(req.TLS)...)
+	}
+
+	metricLabels := semconv.HTTPServerMetricAttributesFromHTTPRequest(o.serverName, req)
+	if route != "" {
+		metricLabels = append(metricLabels, semconv.HTTPRouteKey.String(route))
+	}
+
+	if o.deadlineHeader != "" {
+		if budget, ok := deadline.Extract(o.deadlineHeader, req.Header); ok {
+			span.SetAttributes(LabelKeyHTTPDeadlineBudget.Int64(budget.Milliseconds()))
+			if budget <= 0 {
+				o.metricDeadlineExhausted.Add(ctx, 1, metricLabels...)
+			}
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, start.Add(budget))
+			defer cancel()
+		}
+	}
+
+	// The span ends with the tracker, once the handler has returned and any hijacked connection is closed.
+	tracker := stream.NewTracker(ctx, span, start, metricLabels, stream.Metrics{
+		TimeToFirstByte: o.metricTimeToFirstByte,
+		MessageCount:    o.metricStreamMessageCount,
+		Bytes:           o.metricStreamBytes,
+	})
+	defer tracker.HandlerDone(ctx)
+
+	ctx, deps := dependency.ContextWithRecorder(ctx)
+	ctx, panicked := exception.ContextWithPanic(ctx)
+	rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK, tracker: tracker}
+	if o.traceHeaders.enabled(req) {
+		rw.onHeader = func() {
+			o.traceHeaders.Set(w.Header(), span, start, deps)
+		}
+	}
+	req = req.WithContext(ctx)
+
+	o.handler.ServeHTTP(rw.exposed(), req)
+	// Headers of a response the handler did not write are sent once it returns.
+	rw.writingHeader()
+
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(rw.statusCode)...)
+	span.SetAttributes(header.Attributes(header.ResponsePrefix, w.Header(), o.responseHeaders, o.sensitiveHeaders)...)
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(rw.statusCode))
+	// A panic recovered after the response was written keeps the span as Error.
+	panicked.SetStatus(span)
+	deps.Record(ctx, span, dependency.Metrics{
+		Calls:    o.metricDependencyCalls,
+		Duration: o.metricDependencyDuration,
+	}, metricLabels)
+
+	o.metricRequestCount.Add(ctx, 1, metricLabels...)
+	// Streamed responses and hijacked connections would skew the request duration.
+	if !tracker.Streaming() {
+		elapsedTime := time.Since(start).Milliseconds()
+		o.metricDuration.Record(ctx, elapsedTime, metricLabels...)
+	}
+}
+
+// A ConnState represents the state of a client connection to a server.
+type ConnState = http.ConnState
+
+// Server wraps an http.Server with connection and shutdown telemetry.
+// Methods not overridden here, such as ListenAndServe, are promoted from the embedded http.Server.
+type Server struct {
+	*http.Server
+
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	serverName     string
+	operationName  string
+
+	tracer                  trace.Tracer
+	meter                   metric.Meter
+	metricOpenConnections   metric.Int64UpDownCounter
+	metricIdleConnections   metric.Int64UpDownCounter
+	metricActiveConnections metric.Int64UpDownCounter
+	metricNewConnCount      metric.Int64Counter
+	metricClosedConnCount   metric.Int64Counter
+	metricReusedConnCount   metric.Int64Counter
+
+	inFlight int64
+	mu       sync.Mutex
+	conns    map[net.Conn]ConnState
+	connHook func(net.Conn, ConnState)
+}
+
+// NewServer wraps the provided http.Server, hooking ConnState to record connection metrics
+// and counting in-flight requests so that Shutdown can report how many were drained.
+// It replaces the Handler and ConnState fields of srv, which must therefore not be serving
+// yet nor be passed to NewServer again; the handler and any ConnState hook already set
+// on srv are still called.
+func NewServer(srv *http.Server, opts ...Option) (*Server, error) {
+	c, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		Server:                  srv,
+		tracerProvider:          c.tracerProvider,
+		meterProvider:           c.meterProvider,
+		serverName:              c.serverName,
+		operationName:           c.operationName,
+		tracer:                  c.tracer,
+		meter:                   c.meter,
+		metricOpenConnections:   c.metricServerOpenConnections,
+		metricIdleConnections:   c.metricServerIdleConnections,
+		metricActiveConnections: c.metricServerActiveConnections,
+		metricNewConnCount:      c.metricServerNewConnCount,
+		metricClosedConnCount:   c.metricServerClosedConnCount,
+		metricReusedConnCount:   c.metricServerReusedConnCount,
+		conns:                   make(map[net.Conn]ConnState),
+		connHook:                srv.ConnState,
+	}
+
+	handler := srv.Handler
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+	srv.Handler = http.HandlerFunc(func(w ResponseWriter, req *Request) {
+		atomic.AddInt64(&s.inFlight, 1)
+		defer atomic.AddInt64(&s.inFlight, -1)
+		handler.ServeHTTP(w, req)
+	})
+	srv.ConnState = s.connState
+
+	return s, nil
+}
+
+// ListenAndServe listens on the TCP network address addr and then calls Serve with handler
+// to handle requests on incoming connections, recording connection metrics.
+func ListenAndServe(addr string, handler Handler, opts ...Option) error {
+	s, err := NewServer(&http.Server{Addr: addr, Handler: handler}, opts...)
+	if err != nil {
+		return err
+	}
+	return s.ListenAndServe()
+}
+
+// ListenAndServeTLS acts identically to ListenAndServe, except that it expects HTTPS connections.
+func ListenAndServeTLS(addr, certFile, keyFile string, handler Handler, opts ...Option) error {
+	s, err := NewServer(&http.Server{Addr: addr, Handler: handler}, opts...)
+	if err != nil {
+		return err
+	}
+	return s.ListenAndServeTLS(certFile, keyFile)
+}
+
+// Shutdown gracefully shuts down the server inside a span that records
+// how many in-flight requests were drained.
+func (s *Server) Shutdown(ctx context.Context) error {
+	inFlight := atomic.LoadInt64(&s.inFlight)
+	ctx, span := s.tracer.Start(ctx, s.operationName+".shutdown",
+		trace.WithAttributes(
+			semconv.HTTPServerNameKey.String(s.serverName),
+			LabelKeyHTTPServerInFlightRequests.Int64(inFlight),
+		),
+	)
+	defer span.End()
+
+	err := s.Server.Shutdown(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.SetAttributes(LabelKeyHTTPServerDrainedRequests.Int64(inFlight - atomic.LoadInt64(&s.inFlight)))
+
+	return err
+}
+
+func (s *Server) connState(conn net.Conn, state ConnState) {
+	ctx := context.Background()
+	labels := []label.KeyValue{semconv.HTTPServerNameKey.String(s.serverName)}
+
+	s.mu.Lock()
+	prev, ok := s.conns[conn]
+	switch state {
+	case http.StateNew:
+		s.conns[conn] = state
+	case http.StateActive, http.StateIdle:
+		if ok {
+			s.conns[conn] = state
+		}
+	case http.StateHijacked, http.StateClosed:
+		delete(s.conns, conn)
+	}
+	s.mu.Unlock()
+
+	// Connections accepted before the hook was installed are not tracked,
+	// as the gauges would never see them close.
+	switch {
+	case state == http.StateNew:
+		s.metricOpenConnections.Add(ctx, 1, labels...)
+		s.metricNewConnCount.Add(ctx, 1, labels...)
+	case !ok:
+	case state == http.StateActive:
+		s.metricActiveConnections.Add(ctx, 1, labels...)
+		if prev == http.StateIdle {
+			s.metricIdleConnections.Add(ctx, -1, labels...)
+			s.metricReusedConnCount.Add(ctx, 1, labels...)
+		}
+	case state == http.StateIdle:
+		s.metricIdleConnections.Add(ctx, 1, labels...)
+		if prev == http.StateActive {
+			s.metricActiveConnections.Add(ctx, -1, labels...)
+		}
+	case state == http.StateHijacked, state == http.StateClosed:
+		s.metricOpenConnections.Add(ctx, -1, labels...)
+		s.metricClosedConnCount.Add(ctx, 1, labels...)
+		switch prev {
+		case http.StateActive:
+			s.metricActiveConnections.Add(ctx, -1, labels...)
+		case http.StateIdle:
+			s.metricIdleConnections.Add(ctx, -1, labels...)
+		}
+	}
+
+	if s.connHook != nil {
+		s.connHook(conn, state)
+	}
+}
+
+// responseWriter records the status code written by the wrapped handler,
+// and reports streaming and hijacking to its tracker, if any.
+// onHeader, if set, is called once just before the response headers are written.
+type responseWriter struct {
+	ResponseWriter
+
+	statusCode  int
+	wroteHeader bool
+	tracker     *stream.Tracker
+	onHeader    func()
+}
+
+var (
+	_ http.Flusher  = &responseWriter{}
+	_ http.Hijacker = &responseWriter{}
+)
+
+func (w *responseWriter) writingHeader() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.onHeader != nil {
+		w.onHeader()
+	}
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.statusCode = statusCode
+		w.writingHeader()
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.writingHeader()
+	n, err := w.ResponseWriter.Write(b)
+	w.tracker.Wrote(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.writingHeader()
+		f.Flush()
+		w.tracker.Flushed()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := hijack(w.ResponseWriter)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !w.wroteHeader {
+		w.statusCode = http.StatusSwitchingProtocols
+		w.wroteHeader = true
+	}
+	conn, rw = w.tracker.Hijacked(conn, rw)
+	return conn, rw, nil
+}
+
+// exposed returns w along with the io.ReaderFrom and http.Pusher of the writer it wraps, if any,
+// so that sendfile and HTTP/2 server push keep working behind it.
+func (w *responseWriter) exposed() ResponseWriter {
+	_, isReaderFrom := w.ResponseWriter.(io.ReaderFrom)
+	_, isPusher := w.ResponseWriter.(http.Pusher)
+	switch {
+	case isReaderFrom && isPusher:
+		return readerFromPusherWriter{w}
+	case isReaderFrom:
+		return readerFromWriter{w}
+	case isPusher:
+		return pusherWriter{w}
+	default:
+		return w
+	}
+}
+
+type readerFromWriter struct{ *responseWriter }
+
+type pusherWriter struct{ *responseWriter }
+
+type readerFromPusherWriter struct{ *responseWriter }
+
+func (w readerFromWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.writingHeader()
+	n, err := w.ResponseWriter.(io.ReaderFrom).ReadFrom(r)
+	w.tracker.Wrote(int(n))
+	return n, err
+}
+
+func (w pusherWriter) Push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+func (w readerFromPusherWriter) ReadFrom(r io.Reader) (int64, error) {
+	return readerFromWriter(w).ReadFrom(r)
+}
+
+func (w readerFromPusherWriter) Push(target string, opts *http.PushOptions) error {
+	return pusherWriter(w).Push(target, opts)
+}
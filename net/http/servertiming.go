@@ -0,0 +1,17 @@
+package http
+
+import "github.com/otel-contrib/instrumentation/internal/servertiming"
+
+// TraceHeadersFilter reports whether the traceresponse and Server-Timing headers
+// are sent in response to req, e.g. to keep them from external clients.
+type TraceHeadersFilter func(req *Request) bool
+
+// traceHeaders decides which of the traceresponse and Server-Timing headers are sent, and to which requests.
+type traceHeaders struct {
+	servertiming.Headers
+	filter TraceHeadersFilter
+}
+
+func (th *traceHeaders) enabled(req *Request) bool {
+	return th.Headers.Enabled() && (th.filter == nil || th.filter(req))
+}
@@ -0,0 +1,17 @@
+package http
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// hijack hijacks the connection of w, if it supports it.
+func hijack(w ResponseWriter) (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("http: response does not implement http.Hijacker")
+	}
+	return h.Hijack()
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestServer(t *testing.T, h http.HandlerFunc) (*httptest.Server, *oteltest.StandardSpanRecorder) {
+	sr := new(oteltest.StandardSpanRecorder)
+	handler, err := NewHandler(h, WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv, sr
+}
+
+// waitForServerSpan returns the server span once it has ended, which may happen after
+// the client is done with the response.
+func waitForServerSpan(t *testing.T, sr *oteltest.StandardSpanRecorder) *oteltest.Span {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		for _, span := range sr.Completed() {
+			if span.SpanKind() == trace.SpanKindServer {
+				return span
+			}
+		}
+	}
+	t.Fatal("server span never ended")
+	return nil
+}
+
+func TestHandlerStream(t *testing.T) {
+	srv, sr := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "message %d\n", i)
+			w.(http.Flusher).Flush()
+		}
+	})
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if got := strings.Count(string(body), "\n"); got != 3 {
+		t.Fatalf("received %d messages, want 3", got)
+	}
+
+	attrs := waitForServerSpan(t, sr).Attributes()
+	if got := attrs[LabelKeyHTTPStreamKind].AsString(); got != "stream" {
+		t.Errorf("%s = %q, want %q", LabelKeyHTTPStreamKind, got, "stream")
+	}
+	if got := attrs[LabelKeyHTTPStreamMessages].AsInt64(); got != 3 {
+		t.Errorf("%s = %d, want 3", LabelKeyHTTPStreamMessages, got)
+	}
+	if got := attrs[LabelKeyHTTPStreamCloseReason].AsString(); got != "server_closed" {
+		t.Errorf("%s = %q, want %q", LabelKeyHTTPStreamCloseReason, got, "server_closed")
+	}
+}
+
+func TestHandlerHijacked(t *testing.T) {
+	handlerDone := make(chan struct{})
+	srv, sr := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		defer close(handlerDone)
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, _ = rw.WriteString("hello\n")
+		_ = rw.Flush()
+
+		// The connection outlives the handler, until the client closes it.
+		go func() {
+			defer conn.Close()
+			_, _ = ioutil.ReadAll(rw)
+		}()
+	})
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: %s\r\n\r\n", srv.Listener.Addr())
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "hello\n" {
+		t.Fatalf("received %q, want %q", line, "hello\n")
+	}
+
+	<-handlerDone
+	time.Sleep(50 * time.Millisecond)
+	for _, span := range sr.Completed() {
+		if span.SpanKind() == trace.SpanKindServer {
+			t.Fatal("server span ended while the hijacked connection was still open")
+		}
+	}
+	conn.Close()
+
+	attrs := waitForServerSpan(t, sr).Attributes()
+	if got := attrs[LabelKeyHTTPStreamKind].AsString(); got != "hijacked" {
+		t.Errorf("%s = %q, want %q", LabelKeyHTTPStreamKind, got, "hijacked")
+	}
+	if got := attrs[LabelKeyHTTPStreamBytes].AsInt64(); got != 6 {
+		t.Errorf("%s = %d, want 6", LabelKeyHTTPStreamBytes, got)
+	}
+	if got := attrs[LabelKeyHTTPStreamCloseReason].AsString(); got != "client_closed" {
+		t.Errorf("%s = %q, want %q", LabelKeyHTTPStreamCloseReason, got, "client_closed")
+	}
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	templateSegmentID   = "{id}"
+	templateSegmentUUID = "{uuid}"
+)
+
+type urlTemplateType struct{}
+
+var urlTemplateContextKey = &urlTemplateType{}
+
+// urlTemplate is a path pattern such as /users/{id}/orders, where segments
+// enclosed in braces match any single path segment.
+type urlTemplate struct {
+	template string
+	segments []string
+}
+
+type urlTemplates []urlTemplate
+
+func newURLTemplate(template string) urlTemplate {
+	return urlTemplate{
+		template: template,
+		segments: splitPath(template),
+	}
+}
+
+// URLTemplateFromContext returns the URL template that matched the outbound request carrying ctx.
+// An empty string is returned if the request was not sent by the instrumented transport.
+func URLTemplateFromContext(ctx context.Context) string {
+	template, _ := ctx.Value(urlTemplateContextKey).(string)
+	return template
+}
+
+func (t urlTemplate) match(segments []string) bool {
+	if len(t.segments) != len(segments) {
+		return false
+	}
+	for i, s := range t.segments {
+		if isTemplateParam(s) {
+			continue
+		}
+		if s != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// template returns the first registered template matching path. If none matches,
+// numeric and UUID segments of path are collapsed into {id} and {uuid}.
+func (ts urlTemplates) template(path string) string {
+	segments := splitPath(path)
+	for _, t := range ts {
+		if t.match(segments) {
+			return t.template
+		}
+	}
+
+	for i, s := range segments {
+		switch {
+		case isNumeric(s):
+			segments[i] = templateSegmentID
+		case isUUID(s):
+			segments[i] = templateSegmentUUID
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func isTemplateParam(s string) bool {
+	return len(s) > 2 && s[0] == '{' && s[len(s)-1] == '}'
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !isHex(r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isHex(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
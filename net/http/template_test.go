@@ -0,0 +1,43 @@
+package http
+
+import "testing"
+
+func TestURLTemplates(t *testing.T) {
+	ts := urlTemplates{
+		newURLTemplate("/users/{id}/orders"),
+		newURLTemplate("/repos/{owner}/{repo}"),
+		newURLTemplate("/users/me"),
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/users/42/orders", want: "/users/{id}/orders"},
+		{path: "/users/alice/orders/", want: "/users/{id}/orders"},
+		{path: "/repos/golang/go", want: "/repos/{owner}/{repo}"},
+		{path: "/users/me", want: "/users/me"},
+		{path: "/users/42/orders/7", want: "/users/{id}/orders/{id}"},
+		{path: "/items/123e4567-e89b-12d3-a456-426614174000", want: "/items/{uuid}"},
+		{path: "/items/123E4567-E89B-12D3-A456-426614174000/parts", want: "/items/{uuid}/parts"},
+		{path: "/items/123e4567e89b12d3a456426614174000", want: "/items/123e4567e89b12d3a456426614174000"},
+		{path: "/items/12a", want: "/items/12a"},
+		{path: "/", want: "/"},
+		{path: "", want: "/"},
+	}
+	for _, tt := range tests {
+		if got := ts.template(tt.path); got != tt.want {
+			t.Errorf("template(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestURLTemplatesFirstMatchWins(t *testing.T) {
+	ts := urlTemplates{
+		newURLTemplate("/users/{id}"),
+		newURLTemplate("/users/me"),
+	}
+	if got, want := ts.template("/users/me"), "/users/{id}"; got != want {
+		t.Errorf("template(%q) = %q, want %q", "/users/me", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// peerCertExpiryTTL is how long the certificate of a host no longer contacted keeps being reported.
+const peerCertExpiryTTL = 24 * time.Hour
+
+// peerCertExpiry holds the expiry of the latest certificate seen for each upstream host.
+// It is shared by every transport, as the observer instrument is registered once per meter.
+var peerCertExpiry = &certExpiryTracker{certs: make(map[string]peerCert)}
+
+type certExpiryTracker struct {
+	mu    sync.Mutex
+	certs map[string]peerCert
+}
+
+type peerCert struct {
+	notAfter time.Time
+	lastSeen time.Time
+}
+
+func (t *certExpiryTracker) record(host string, state *tls.ConnectionState) {
+	if len(state.PeerCertificates) == 0 {
+		return
+	}
+	t.mu.Lock()
+	t.certs[host] = peerCert{notAfter: state.PeerCertificates[0].NotAfter, lastSeen: time.Now()}
+	t.mu.Unlock()
+}
+
+func (t *certExpiryTracker) observe(ctx context.Context, result metric.Int64ObserverResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for host, cert := range t.certs {
+		if time.Since(cert.lastSeen) > peerCertExpiryTTL {
+			delete(t.certs, host)
+			continue
+		}
+		days := int64(time.Until(cert.notAfter) / (24 * time.Hour))
+		result.Observe(days, semconv.NetPeerNameKey.String(host))
+	}
+}
@@ -1,13 +1,24 @@
 package http
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptrace"
 	"time"
 
+	"github.com/otel-contrib/instrumentation/internal/bodycapture"
+	"github.com/otel-contrib/instrumentation/internal/deadline"
+	"github.com/otel-contrib/instrumentation/internal/dependency"
+	"github.com/otel-contrib/instrumentation/internal/header"
+	"github.com/otel-contrib/instrumentation/internal/reqctx"
+	"github.com/otel-contrib/instrumentation/internal/tlsinfo"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/semconv"
 	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/unit"
 )
 
 // DefaultTransport is the default implementation of Transport and is used by DefaultClient.
@@ -24,12 +35,31 @@ type otelTransport struct {
 	propagator        propagation.TextMapPropagator
 	operationName     string
 	spanNameFormatter SpanNameFormatter
+	clientTrace       bool
+	urlTemplates      urlTemplates
+	requestHeaders    []string
+	responseHeaders   []string
+	sensitiveHeaders  header.Set
+	bodyCapture       bodycapture.Config
+	redirectChain     bool
+	propagationPolicy *propagationPolicy
+	deadlineHeader    string
 
 	tracer                   trace.Tracer
 	meter                    metric.Meter
 	metricDuration           metric.Int64ValueRecorder
 	metricRequestCount       metric.Int64Counter
 	metricRequestFailedCount metric.Int64Counter
+	metricActiveRequests     metric.Int64UpDownCounter
+	metricConnCount          metric.Int64Counter
+	metricGetConnDuration    metric.Int64ValueRecorder
+	metricDNSDuration        metric.Int64ValueRecorder
+	metricConnectDuration    metric.Int64ValueRecorder
+	metricTLSDuration        metric.Int64ValueRecorder
+	metricFirstByteDuration  metric.Int64ValueRecorder
+	metricRequestSize        metric.Int64ValueRecorder
+	metricResponseSize       metric.Int64ValueRecorder
+	metricPeerCertExpiry     metric.Int64ValueObserver
 }
 
 var _ RoundTripper = &otelTransport{}
@@ -53,11 +83,40 @@ func NewOTelTransport(rt RoundTripper, opts ...Option) (RoundTripper, error) {
 		propagator:               c.propagator,
 		operationName:            c.operationName,
 		spanNameFormatter:        c.spanNameFormatter,
+		clientTrace:              c.clientTrace,
+		urlTemplates:             c.urlTemplates,
+		requestHeaders:           c.requestHeaders,
+		responseHeaders:          c.responseHeaders,
+		sensitiveHeaders:         c.sensitiveHeaders,
+		bodyCapture:              c.bodyCapture,
+		redirectChain:            c.redirectChain,
+		propagationPolicy:        c.propagationPolicy,
+		deadlineHeader:           c.deadlineHeader,
 		tracer:                   c.tracer,
 		meter:                    c.meter,
 		metricDuration:           c.metricClientDuration,
 		metricRequestCount:       c.metricClientRequestCount,
 		metricRequestFailedCount: c.metricClientRequestFailedCount,
+		metricActiveRequests:     c.metricClientActiveRequests,
+		metricConnCount:          c.metricClientConnCount,
+		metricGetConnDuration:    c.metricClientGetConnDuration,
+		metricDNSDuration:        c.metricClientDNSDuration,
+		metricConnectDuration:    c.metricClientConnectDuration,
+		metricTLSDuration:        c.metricClientTLSDuration,
+		metricFirstByteDuration:  c.metricClientFirstByteDuration,
+		metricRequestSize:        c.metricClientRequestSize,
+		metricResponseSize:       c.metricClientResponseSize,
+	}
+
+	// Only transports see peer certificates, so the observer is not registered by newConfig.
+	o.metricPeerCertExpiry, err = o.meter.NewInt64ValueObserver(
+		metricHTTPClientPeerCertExpiry,
+		peerCertExpiry.observe,
+		metric.WithDescription("days until the peer certificate expires"),
+		metric.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, err
 	}
 
 	return o, nil
@@ -66,33 +125,165 @@ func NewOTelTransport(rt RoundTripper, opts ...Option) (RoundTripper, error) {
 func (o *otelTransport) RoundTrip(req *Request) (*Response, error) {
 	start := time.Now()
 
+	if ctx := reqctx.Unwrap(req.Context()); ctx != req.Context() {
+		req = req.WithContext(ctx)
+	}
+
+	template := o.urlTemplates.template(req.URL.Path)
+	req = req.WithContext(context.WithValue(req.Context(), urlTemplateContextKey, template))
+
 	netAttrs := semconv.NetAttributesFromHTTPRequest("tcp", req)
 	httpClientAttrs := semconv.HTTPClientAttributesFromHTTPRequest(req)
 	attrs := append(netAttrs, httpClientAttrs...)
-	metricLabels := attrs
-	ctx, span := o.tracer.Start(req.Context(), o.spanNameFormatter(o.operationName, req),
+	attrs = append(attrs, semconv.HTTPRouteKey.String(template))
+	metricLabels := clientMetricLabels(attrs)
+
+	parentCtx := req.Context()
+	var chain *redirectChain
+	if o.redirectChain {
+		if chain = redirectChainOf(req); chain == nil {
+			chain = o.startRedirectChain(parentCtx, req)
+		}
+		var hop int
+		parentCtx, hop = chain.startRedirectHop(parentCtx)
+		attrs = append(attrs, LabelKeyHTTPRedirectHop.Int(hop))
+	}
+
+	ctx, span := o.tracer.Start(parentCtx, o.spanNameFormatter(o.operationName, req),
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(attrs...),
+		trace.WithAttributes(header.Attributes(header.RequestPrefix, req.Header, o.requestHeaders, o.sensitiveHeaders)...),
 	)
-	defer span.End()
+
+	hostLabels := []label.KeyValue{semconv.NetPeerNameKey.String(req.URL.Hostname())}
+	o.metricActiveRequests.Add(ctx, 1, hostLabels...)
+
+	ctx = httptrace.WithClientTrace(ctx, o.connPoolTrace(ctx, hostLabels))
+	if o.clientTrace {
+		ct := newClientTracer(ctx, o, metricLabels)
+		defer ct.endAll()
+		ctx = httptrace.WithClientTrace(ctx, ct.clientTrace())
+	}
 
 	req = req.WithContext(ctx)
-	o.propagator.Inject(ctx, req.Header)
+	propagated := o.propagationPolicy.inject(ctx, o.propagator, req)
+	injectDeadline(ctx, o.deadlineHeader, propagated, req, span)
+
+	if req.ContentLength > 0 {
+		o.metricRequestSize.Record(ctx, req.ContentLength, metricLabels...)
+	}
+
+	var reqBody *bodycapture.Buffer
+	if o.bodyCapture.Enabled() && req.Body != nil && req.Body != http.NoBody {
+		req.Body, reqBody = o.bodyCapture.Tee(req.Body)
+	}
 
 	resp, err := o.rt.RoundTrip(req)
 	if err != nil {
+		o.bodyCapture.Record(span, bodycapture.EventRequestBody, req.Header.Get("Content-Type"), reqBody)
 		span.RecordError(err)
 		o.metricRequestFailedCount.Add(ctx, 1, metricLabels...)
-	} else {
-		httpAttributes := semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)
-		span.SetAttributes(httpAttributes...)
-		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(resp.StatusCode))
-		metricLabels = append(attrs, httpAttributes...)
+		o.end(ctx, span, start, metricLabels, hostLabels)
+		chain.hopDone(true)
+		return resp, err
 	}
 
-	o.metricRequestCount.Add(ctx, 1, metricLabels...)
-	elapsedTime := time.Since(start).Milliseconds()
-	o.metricDuration.Record(ctx, elapsedTime, metricLabels...)
+	httpAttributes := semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)
+	span.SetAttributes(httpAttributes...)
+	span.SetAttributes(header.Attributes(header.ResponsePrefix, resp.Header, o.responseHeaders, o.sensitiveHeaders)...)
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(resp.StatusCode))
+	if resp.TLS != nil {
+		span.SetAttributes(tlsinfo.Attributes(resp.TLS)...)
+		peerCertExpiry.record(req.URL.Hostname(), resp.TLS)
+	}
+	metricLabels = append(metricLabels, httpAttributes...)
+
+	final := true
+	if o.redirectChain && isRedirect(req, resp) {
+		final = false
+		if resp.Request == nil {
+			resp.Request = req
+		}
+		span.SetAttributes(LabelKeyHTTPRedirectLocation.String(resp.Header.Get("Location")))
+	}
+
+	captureBody := o.bodyCapture.Enabled() && resp.StatusCode >= http.StatusBadRequest
+	if captureBody {
+		o.bodyCapture.Record(span, bodycapture.EventRequestBody, req.Header.Get("Content-Type"), reqBody)
+	}
+
+	if resp.Body == nil || resp.Body == http.NoBody {
+		o.end(ctx, span, start, metricLabels, hostLabels)
+		chain.hopDone(final)
+		if !final {
+			resp.Body = chain.redirectBody(resp.Body)
+		}
+		return resp, err
+	}
+
+	var respBody *bodycapture.Buffer
+	if captureBody {
+		resp.Body, respBody = o.bodyCapture.Tee(resp.Body)
+	}
+
+	// The span ends once the body has been read to EOF or closed,
+	// so that it covers the whole download rather than just the headers.
+	resp.Body = newResponseBody(resp.Body, func(n int64, readErr error) {
+		span.SetAttributes(semconv.HTTPResponseContentLengthKey.Int64(n))
+		if readErr != nil {
+			span.RecordError(readErr)
+			span.SetStatus(codes.Error, readErr.Error())
+			o.metricRequestFailedCount.Add(ctx, 1, metricLabels...)
+		}
+		if captureBody {
+			o.bodyCapture.Record(span, bodycapture.EventResponseBody, resp.Header.Get("Content-Type"), respBody)
+		}
+		o.metricResponseSize.Record(ctx, n, metricLabels...)
+		o.end(ctx, span, start, metricLabels, hostLabels)
+		chain.hopDone(final)
+	})
+	if !final {
+		resp.Body = chain.redirectBody(resp.Body)
+	}
 
 	return resp, err
 }
+
+func (o *otelTransport) end(ctx context.Context, span trace.Span, start time.Time, metricLabels, hostLabels []label.KeyValue) {
+	o.metricActiveRequests.Add(ctx, -1, hostLabels...)
+	o.metricRequestCount.Add(ctx, 1, metricLabels...)
+	elapsed := time.Since(start)
+	o.metricDuration.Record(ctx, elapsed.Milliseconds(), metricLabels...)
+	dependency.FromContext(ctx).Add(dependency.Upstream, elapsed)
+	span.End()
+}
+
+// injectDeadline replaces the header name of req with the budget left before the deadline of ctx,
+// which goes along with the trace context to the destinations the propagation policy allows,
+// and records the budget on span. Nothing is done if name is empty.
+func injectDeadline(ctx context.Context, name string, propagated bool, req *Request, span trace.Span) {
+	if name == "" {
+		return
+	}
+	req.Header.Del(name)
+	if !propagated {
+		return
+	}
+	if budget, ok := deadline.Inject(ctx, name, req.Header); ok {
+		span.SetAttributes(LabelKeyHTTPDeadlineBudget.Int64(budget))
+	}
+}
+
+// clientMetricLabels drops the high-cardinality attributes, such as http.url,
+// which are kept on the span but must not be used as metric labels.
+func clientMetricLabels(attrs []label.KeyValue) []label.KeyValue {
+	labels := make([]label.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		switch attr.Key {
+		case semconv.HTTPURLKey, semconv.HTTPUserAgentKey, semconv.HTTPRequestContentLengthKey:
+			continue
+		}
+		labels = append(labels, attr)
+	}
+	return labels
+}